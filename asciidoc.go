@@ -0,0 +1,116 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// AsciiDocLiteral returns body as an AsciiDoc literal block delimited by lines
+// of four periods.  The body is dedented and stripped of leading and trailing
+// blank lines.  If a line of body is itself a delimiter, longer delimiters are
+// used.  The result ends in a newline.
+func AsciiDocLiteral(body string) string {
+	body = reindent("", body)
+	delim := "...."
+	for _, line := range strings.Split(body, "\n") {
+		if len(line) >= len(delim) && strings.Trim(line, ".") == "" {
+			delim = line + "."
+		}
+	}
+	return delim + "\n" + body + delim + "\n"
+}
+
+// AsciiDocBullet returns the marker of an AsciiDoc unordered list item at
+// depth, starting with "*" at depth 1.
+func AsciiDocBullet(depth int) string {
+	if depth < 1 {
+		depth = 1
+	}
+	return strings.Repeat("*", depth)
+}
+
+// AsciiDocTerm returns the marker of an AsciiDoc description list item for
+// term at depth, starting with "term::" at depth 1.
+func AsciiDocTerm(term string, depth int) string {
+	if depth < 1 {
+		depth = 1
+	}
+	return term + strings.Repeat(":", depth+1)
+}
+
+// An asciiDocItem writes a single AsciiDoc list item.
+type asciiDocItem struct {
+	w       io.Writer
+	marker  []byte
+	out     outbuf
+	err     error
+	started bool // the marker has been written
+	sol     bool // we are at the start of a line
+	blank   bool // blank lines have been dropped since the last line
+}
+
+// NewAsciiDocItem returns a writer that writes what is written to it to w as
+// the content of a single AsciiDoc list item started by marker, such as one
+// returned by AsciiDocBullet or AsciiDocTerm.  The marker and a space are
+// written before the first line.  The blank lines between paragraphs and
+// blocks, such as those returned by AsciiDocLiteral, are replaced with the
+// list continuation "+" so they remain part of the item.  Leading and trailing
+// blank lines are dropped.
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewAsciiDocItem(w io.Writer, marker string) io.Writer {
+	return &asciiDocItem{
+		w:      w,
+		marker: []byte(marker + " "),
+		sol:    true,
+	}
+}
+
+func (a *asciiDocItem) Write(buf []byte) (int, error) {
+	if a.err != nil {
+		return 0, a.err
+	}
+	for len(buf) > 0 {
+		line := buf
+		if x := bytes.IndexByte(buf, '\n'); x >= 0 {
+			line = buf[:x+1]
+		}
+		buf = buf[len(line):]
+		if a.sol {
+			if line[0] == '\n' {
+				a.out.drop(1)
+				a.blank = a.started
+				continue
+			}
+			switch {
+			case !a.started:
+				a.out.add(a.marker)
+				a.started = true
+			case a.blank:
+				a.out.addString("+\n")
+			}
+			a.blank = false
+		}
+		a.out.copy(line)
+		a.sol = line[len(line)-1] == '\n'
+	}
+	n, err := a.out.writeTo(a.w)
+	a.err = err
+	return n, err
+}
@@ -0,0 +1,88 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestAsciiDocLiteral(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{in: "\n  a\n    b\n\n", out: "....\na\n  b\n....\n"},
+		{in: "x\n....\ny", out: ".....\nx\n....\ny\n.....\n"},
+	} {
+		if got := AsciiDocLiteral(tt.in); got != tt.out {
+			t.Errorf("AsciiDocLiteral(%q) got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestAsciiDocMarkers(t *testing.T) {
+	for _, tt := range []struct {
+		got, want string
+	}{
+		{AsciiDocBullet(0), "*"},
+		{AsciiDocBullet(3), "***"},
+		{AsciiDocTerm("CPU", 1), "CPU::"},
+		{AsciiDocTerm("CPU", 2), "CPU:::"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestNewAsciiDocItem(t *testing.T) {
+	for _, tt := range []struct {
+		marker string
+		in     []string
+		out    string
+	}{
+		{marker: "*", in: []string{"item\n"}, out: "* item\n"},
+		{marker: "**", in: []string{"\n\nfirst\nline\n\nsecond\n\n\n"}, out: "** first\nline\n+\nsecond\n"},
+		{
+			marker: AsciiDocTerm("Run", 1),
+			in:     []string{"Runs it.\n", "\n", AsciiDocLiteral("$ run")},
+			out:    "Run:: Runs it.\n+\n....\n$ run\n....\n",
+		},
+		{marker: "*", in: []string{"a", "b\n", "\n", "c"}, out: "* ab\n+\nc"},
+	} {
+		var buf bytes.Buffer
+		w := NewAsciiDocItem(&buf, tt.marker)
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestNewAsciiDocItemNested(t *testing.T) {
+	// A nested item may be written through its parent.
+	var buf bytes.Buffer
+	w := NewAsciiDocItem(&buf, AsciiDocBullet(1))
+	io.WriteString(w, "outer\n")
+	io.WriteString(NewAsciiDocItem(w, AsciiDocBullet(2)), "inner\n\nmore\n")
+	if got, want := buf.String(), "* outer\n** inner\n+\nmore\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
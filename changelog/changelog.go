@@ -0,0 +1,188 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package changelog builds release notes and changelogs made of sections of
+// nested bullet lists.  The text of each bullet is wrapped with a hanging
+// indent and the changelog is rendered as either Markdown or plain text.  For
+// example,
+//
+//	c := changelog.New("Changelog")
+//	r := c.Section("v1.1.0")
+//	added := r.Section("Added")
+//	added.Item("WithGutter pads each level's prefix to a common width.")
+//	fixed := r.Section("Fixed")
+//	fixed.Item("Nested writers no longer share prefix storage.").
+//		Item("Reported as issue 12.")
+//	c.Write(os.Stdout, changelog.Markdown, 38)
+//
+// writes
+//
+//	# Changelog
+//
+//	## v1.1.0
+//
+//	### Added
+//
+//	- WithGutter pads each level's prefix
+//	  to a common width.
+//
+//	### Fixed
+//
+//	- Nested writers no longer share
+//	  prefix storage.
+//	  - Reported as issue 12.
+package changelog
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pborman/indent"
+)
+
+// A Format is a way of rendering a changelog.
+type Format int
+
+const (
+	// Markdown renders headings with # and bullets with -.
+	Markdown Format = iota
+	// Text renders the top headings underlined and bullets with *, -, and
+	// + depending on their depth.
+	Text
+)
+
+// A Section is a titled list of items followed by nested sections.
+type Section struct {
+	title    string
+	items    []*Item
+	sections []*Section
+}
+
+// An Item is a bullet with its own nested list of items.
+type Item struct {
+	text  string
+	items []*Item
+}
+
+// New returns a new changelog, which is a top level section, titled title.
+func New(title string) *Section {
+	return &Section{title: title}
+}
+
+// Section adds and returns a new section of s titled title.  Sections are
+// rendered after the items of s.
+func (s *Section) Section(title string) *Section {
+	ns := &Section{title: title}
+	s.sections = append(s.sections, ns)
+	return ns
+}
+
+// Item adds and returns a new item of s with the provided text.  The text is
+// wrapped when rendered so newlines within a paragraph are not significant.
+func (s *Section) Item(text string) *Item {
+	i := &Item{text: text}
+	s.items = append(s.items, i)
+	return i
+}
+
+// Item adds and returns a new item nested in i.
+func (i *Item) Item(text string) *Item {
+	ni := &Item{text: text}
+	i.items = append(i.items, ni)
+	return ni
+}
+
+// Write writes s to w in format f with lines no wider than width columns.  If
+// width is not positive the lines are not wrapped.
+func (s *Section) Write(w io.Writer, f Format, width int) error {
+	_, err := io.WriteString(w, s.Render(f, width))
+	return err
+}
+
+// Render returns s as it would be written by Write.
+func (s *Section) Render(f Format, width int) string {
+	r := &renderer{f: f, width: width}
+	r.section(s, 0)
+	return r.b.String()
+}
+
+// String returns s rendered as Markdown without wrapping.
+func (s *Section) String() string {
+	return s.Render(Markdown, 0)
+}
+
+// A renderer accumulates a rendered changelog.
+type renderer struct {
+	b     strings.Builder
+	f     Format
+	width int
+}
+
+// block starts a new block of output, separated from the previous block by a
+// blank line.
+func (r *renderer) block() {
+	if r.b.Len() > 0 {
+		r.b.WriteByte('\n')
+	}
+}
+
+func (r *renderer) section(s *Section, depth int) {
+	if s.title != "" {
+		r.block()
+		r.heading(s.title, depth)
+	}
+	if len(s.items) > 0 {
+		r.block()
+		for _, i := range s.items {
+			r.item(i, "", 0)
+		}
+	}
+	for _, ns := range s.sections {
+		r.section(ns, depth+1)
+	}
+}
+
+func (r *renderer) heading(title string, depth int) {
+	if r.f == Markdown {
+		r.b.WriteString(strings.Repeat("#", depth+1) + " " + title + "\n")
+		return
+	}
+	switch depth {
+	case 0:
+		r.b.WriteString(title + "\n" + strings.Repeat("=", len(title)) + "\n")
+	case 1:
+		r.b.WriteString(title + "\n" + strings.Repeat("-", len(title)) + "\n")
+	default:
+		r.b.WriteString(title + ":\n")
+	}
+}
+
+// textMarkers are the bullets used by Text at successive depths.
+var textMarkers = []string{"* ", "- ", "+ "}
+
+func (r *renderer) item(i *Item, prefix string, depth int) {
+	marker := "- "
+	if r.f == Text {
+		marker = textMarkers[depth%len(textMarkers)]
+	}
+	rest := prefix + strings.Repeat(" ", len(marker))
+	text := indent.Hanging(prefix+marker, rest, i.text, r.width)
+	if text == "" {
+		text = prefix + strings.TrimRight(marker, " ") + "\n"
+	}
+	r.b.WriteString(text)
+	for _, ni := range i.items {
+		r.item(ni, rest, depth+1)
+	}
+}
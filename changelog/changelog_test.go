@@ -0,0 +1,92 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package changelog
+
+import "testing"
+
+func testChangelog() *Section {
+	c := New("Changelog")
+	r := c.Section("v1.1.0")
+	added := r.Section("Added")
+	added.Item("WithGutter pads each level's prefix to a common width.")
+	fixed := r.Section("Fixed")
+	fixed.Item("Nested writers no longer share prefix storage.").
+		Item("Reported as issue 12.")
+	old := c.Section("v1.0.0")
+	old.Item("First release.")
+	return c
+}
+
+func TestMarkdown(t *testing.T) {
+	want := `# Changelog
+
+## v1.1.0
+
+### Added
+
+- WithGutter pads each level's prefix
+  to a common width.
+
+### Fixed
+
+- Nested writers no longer share
+  prefix storage.
+  - Reported as issue 12.
+
+## v1.0.0
+
+- First release.
+`
+	if got := testChangelog().Render(Markdown, 38); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestText(t *testing.T) {
+	want := `Changelog
+=========
+
+v1.1.0
+------
+
+Added:
+
+* WithGutter pads each level's prefix
+  to a common width.
+
+Fixed:
+
+* Nested writers no longer share
+  prefix storage.
+  - Reported as issue 12.
+
+v1.0.0
+------
+
+* First release.
+`
+	if got := testChangelog().Render(Text, 38); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnwrapped(t *testing.T) {
+	c := New("")
+	c.Item("a long item that is not wrapped").Item("").Item("c")
+	want := "- a long item that is not wrapped\n  -\n    - c\n"
+	if got := c.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
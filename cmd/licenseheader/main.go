@@ -0,0 +1,88 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Program licenseheader applies a license header to source files.
+//
+// Usage:
+//
+//	licenseheader -header FILE [-w] [-comment PREFIX] FILE...
+//
+// The header is written as line comments using the comment prefix of each
+// file's language, as determined by its extension.  An existing copyright or
+// license header is replaced.  Without -w the new files are written to
+// standard output.  With -w the files are updated in place and only files
+// that change are written.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pborman/indent"
+)
+
+func main() {
+	headerFile := flag.String("header", "", "file containing the header text")
+	write := flag.Bool("w", false, "write the result to the source files")
+	comment := flag.String("comment", "", "comment prefix to use for all files")
+	flag.Usage = indent.FlagUsage(flag.CommandLine, "  ", "\t", 76)
+	flag.Parse()
+	if *headerFile == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	header, err := ioutil.ReadFile(*headerFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	status := 0
+	for _, name := range flag.Args() {
+		if err := apply(name, string(header), *comment, *write); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+// apply applies header to the file name.
+func apply(name, header, comment string, write bool) error {
+	if comment == "" {
+		var ok bool
+		if comment, ok = indent.CommentPrefix(name); !ok {
+			return fmt.Errorf("%s: unknown comment prefix, use -comment", name)
+		}
+	}
+	src, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	out := []byte(indent.ApplyHeader(string(src), header, comment))
+	if !write {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+	if bytes.Equal(src, out) {
+		return nil
+	}
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, out, fi.Mode())
+}
@@ -0,0 +1,77 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+)
+
+// A csvWriter prefixes the records of CSV text.
+type csvWriter struct {
+	w      io.Writer
+	prefix []byte
+	out    outbuf
+	err    error
+	sol    bool
+	quoted bool // we are in a quoted field
+}
+
+// NewCSV returns a writer that prefixes each record of the CSV text written to
+// it with prefix and writes the results to w.  NewCSV understands RFC 4180
+// quoting: a newline within a quoted field is part of the field and is not
+// followed by prefix.  NewCSV returns w if prefix is the empty string.
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewCSV(w io.Writer, prefix string) io.Writer {
+	if len(prefix) == 0 {
+		return w
+	}
+	return &csvWriter{
+		w:      w,
+		prefix: []byte(prefix),
+		sol:    true,
+	}
+}
+
+func (c *csvWriter) Write(buf []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	for len(buf) > 0 {
+		if c.sol {
+			c.out.add(c.prefix)
+			c.sol = false
+		}
+		// An escaped quote ("") toggles quoted twice so it needs no
+		// special handling.
+		x := bytes.IndexAny(buf, "\"\n")
+		if x < 0 {
+			c.out.copy(buf)
+			break
+		}
+		c.out.copy(buf[:x+1])
+		if buf[x] == '"' {
+			c.quoted = !c.quoted
+		} else {
+			c.sol = !c.quoted
+		}
+		buf = buf[x+1:]
+	}
+	n, err := c.out.writeTo(c.w)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,90 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestNewCSV(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{},
+		{
+			in:  []string{"a,b\n"},
+			out: "> a,b\n",
+		}, {
+			in:  []string{"a,b\r\nc,d\r\n"},
+			out: "> a,b\r\n> c,d\r\n",
+		}, {
+			in:  []string{"a,\"b\nc\"\nd,e\n"},
+			out: "> a,\"b\nc\"\n> d,e\n",
+		}, {
+			in:  []string{"a,\"b", "\n", "c\"", "\n", "d"},
+			out: "> a,\"b\nc\"\n> d",
+		}, {
+			in:  []string{"\"say \"\"hi\"\"\nthere\",x\n"},
+			out: "> \"say \"\"hi\"\"\nthere\",x\n",
+		},
+	} {
+		var buf bytes.Buffer
+		w := NewCSV(&buf, "> ")
+		for _, s := range tt.in {
+			if _, err := w.Write([]byte(s)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestNewCSVRoundTrip(t *testing.T) {
+	records := [][]string{
+		{"name", "notes"},
+		{"alice", "line 1\nline 2"},
+		{"bob", "with \"quotes\"\nand more"},
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(NewCSV(&buf, "  "))
+	if err := w.WriteAll(records); err != nil {
+		t.Fatal(err)
+	}
+	want := "  name,notes\n  alice,\"line 1\nline 2\"\n  bob,\"with \"\"quotes\"\"\nand more\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewCSVShortWrite(t *testing.T) {
+	fw := &fakeWriter{left: 6}
+	w := NewCSV(fw, "--")
+	n, err := w.Write([]byte("ab\ncd\n"))
+	if err == nil {
+		t.Fatal("short write did not return an error")
+	}
+	// "--ab\n-" is 3 bytes of input.
+	if n != 3 {
+		t.Errorf("got %d, want 3", n)
+	}
+	if _, err2 := w.Write([]byte("x")); err2 != err {
+		t.Errorf("second write got %v, want %v", err2, err)
+	}
+}
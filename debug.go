@@ -0,0 +1,114 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// WithDepthTags is a debugging aid that causes every line written by the chain
+// of writers to begin with a tag, such as «2», giving the nesting depth of the
+// writer that wrote it.  The writer created by the first call to New has a
+// depth of 1.  The tag precedes the prefix.  This makes it easy to see which
+// writer produced each line of a complex pretty-printer.  A chain without
+// WithDepthTags pays nothing for the feature beyond a single test per Write.
+func WithDepthTags() Option {
+	return func(in *indenter) {
+		in.s.tags = true
+	}
+}
+
+// depth returns the nesting depth of in, which is 1 for an indenter that does
+// not wrap another indenter.
+func (in *indenter) depth() int {
+	d := 0
+	for ; in != nil; in = in.p {
+		d++
+	}
+	return d
+}
+
+// linePrefix returns the bytes that start each line written by in.  This is
+// normally in's prefix.
+func (in *indenter) linePrefix() []byte {
+	if !in.s.tags {
+		return in.prefix
+	}
+	o := in.opts()
+	if o.tagged == nil {
+		tag := "«" + strconv.Itoa(in.depth()) + "»"
+		o.tagged = append([]byte(tag), in.prefix...)
+	}
+	return o.tagged
+}
+
+// WithTrace is a debugging aid that causes the chain of writers to call logf,
+// which is typically log.Printf or a testing.T's Logf method, once for each
+// Write and WriteAll.  Each call reports the depth of the writer, the number
+// of bytes passed, whether the writer was at the start of a line before and
+// after the write, and the number of bytes written along with any error.  A
+// write that did not write all its bytes is noted as a short write.  For
+// example:
+//
+//	indent: «2» Write 12 bytes, sol true->false, wrote 12
+//	indent: «1» Write 6 bytes, sol false->false, wrote 3, short write: EOF
+func WithTrace(logf func(format string, v ...interface{})) Option {
+	return func(in *indenter) {
+		in.s.opts().trace = logf
+	}
+}
+
+// trace reports a call to op to the function set by WithTrace.
+func (in *indenter) trace(op string, size int, sol bool, n int, err error) {
+	switch {
+	case n < size:
+		in.s.opt.trace("indent: «%d» %s %d bytes, sol %v->%v, wrote %d, short write: %v", in.depth(), op, size, sol, in.s.sol, n, err)
+	case err != nil:
+		in.s.opt.trace("indent: «%d» %s %d bytes, sol %v->%v, wrote %d, error: %v", in.depth(), op, size, sol, in.s.sol, n, err)
+	default:
+		in.s.opt.trace("indent: «%d» %s %d bytes, sol %v->%v, wrote %d", in.depth(), op, size, sol, in.s.sol, n)
+	}
+}
+
+// WithRaceCheck is a debugging aid that causes the chain of writers to panic
+// when a Write is made while another Write to the chain is in progress.  The
+// writers returned by New are not safe for concurrent use and concurrent
+// writes otherwise result in corrupted output.  The check is made with an
+// atomic flag so it catches misuse in builds without the race detector, but
+// it only detects writes that actually overlap.
+func WithRaceCheck() Option {
+	return func(in *indenter) {
+		in.s.check = true
+		in.s.opts()
+	}
+}
+
+// debugWrite calls write, which performs the operation op of size bytes,
+// applying the checks of WithRaceCheck and the tracing of WithTrace.
+func (in *indenter) debugWrite(op string, size int, write func() (int, error)) (int, error) {
+	if in.s.check {
+		if !atomic.CompareAndSwapInt32(&in.s.opt.busy, 0, 1) {
+			panic("indent: concurrent " + op + " to a writer that is not safe for concurrent use")
+		}
+		defer atomic.StoreInt32(&in.s.opt.busy, 0)
+	}
+	sol := in.s.sol
+	n, err := write()
+	if in.s.opt.trace != nil {
+		in.trace(op, size, sol, n, err)
+	}
+	return n, err
+}
@@ -0,0 +1,145 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWithDepthTags(t *testing.T) {
+	var buf bytes.Buffer
+	w1 := New(&buf, "> ", WithDepthTags())
+	w2 := New(w1, "  ")
+	w3 := New(w2, "  ")
+	io.WriteString(w1, "a {\n")
+	io.WriteString(w2, "b {\n")
+	io.WriteString(w3, "c\nd\n")
+	io.WriteString(w2, "}\n")
+	io.WriteString(w1, "}\n")
+	want := "" +
+		"«1»> a {\n" +
+		"«2»>   b {\n" +
+		"«3»>     c\n" +
+		"«3»>     d\n" +
+		"«2»>   }\n" +
+		"«1»> }\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The tags may also be turned on by a nested writer.
+	buf.Reset()
+	w1 = New(&buf, "> ")
+	w2 = New(w1, "  ", WithDepthTags())
+	io.WriteString(w1, "a\n")
+	io.WriteString(w2, "b\n")
+	want = "«1»> a\n«2»>   b\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWithDepthTagsSiblings(t *testing.T) {
+	// Siblings must not share the storage of their combined prefixes.
+	var buf bytes.Buffer
+	w := New(&buf, "> ", WithDepthTags())
+	a := New(w, "a ")
+	b := New(w, "b ")
+	io.WriteString(a, "1\n")
+	io.WriteString(b, "2\n")
+	want := "«2»> a 1\n«2»> b 2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithTrace(t *testing.T) {
+	var lines []string
+	logf := func(format string, v ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, v...))
+	}
+	fw := &fakeWriter{left: 14}
+	w1 := New(fw, "> ", WithTrace(logf))
+	w2 := New(w1, "  ")
+	io.WriteString(w1, "a\n")
+	io.WriteString(w2, "bc")
+	io.WriteString(w2, "\n")
+	io.WriteString(w1, "def\n")
+	w1.(interface {
+		WriteAll(...[]byte) (int, error)
+	}).WriteAll([]byte("g"), []byte("h\n"))
+	want := []string{
+		"indent: «1» Write 2 bytes, sol true->true, wrote 2",
+		"indent: «2» Write 2 bytes, sol true->false, wrote 2",
+		"indent: «2» Write 1 bytes, sol false->true, wrote 1",
+		"indent: «1» Write 4 bytes, sol true->false, wrote 1, short write: EOF",
+		"indent: «1» WriteAll 3 bytes, sol false->false, wrote 0, short write: EOF",
+	}
+	if got := strings.Join(lines, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+// blockingWriter blocks each Write until it is released.
+type blockingWriter struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingWriter) Write(buf []byte) (int, error) {
+	b.entered <- struct{}{}
+	<-b.release
+	return len(buf), nil
+}
+
+func TestWithRaceCheck(t *testing.T) {
+	bw := &blockingWriter{
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	w1 := New(bw, "> ", WithRaceCheck())
+	w2 := New(w1, "  ")
+	done := make(chan struct{})
+	go func() {
+		io.WriteString(w1, "a\n")
+		close(done)
+	}()
+	<-bw.entered
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("concurrent write did not panic")
+			}
+		}()
+		io.WriteString(w2, "b\n")
+	}()
+
+	close(bw.release)
+	<-done
+
+	// Writes that do not overlap are fine.
+	go func() {
+		for range bw.entered {
+		}
+	}()
+	io.WriteString(w2, "c\n")
+	io.WriteString(w1, "d\n")
+	close(bw.entered)
+}
@@ -0,0 +1,106 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "strings"
+
+// isBlank returns true if line, which may include a trailing newline, only
+// contains spaces and tabs.
+func isBlank(line string) bool {
+	return strings.TrimRight(line, " \t\r\n") == ""
+}
+
+// leading returns the leading spaces and tabs of line.
+func leading(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// commonIndent returns the longest string of spaces and tabs that starts all
+// the non-blank lines in s.
+func commonIndent(s string) string {
+	common := ""
+	first := true
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if isBlank(line) {
+			continue
+		}
+		ws := leading(line)
+		if first {
+			common = ws
+			first = false
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(ws) && common[n] == ws[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	return common
+}
+
+// Dedent removes the longest common leading whitespace from the non-blank
+// lines of s, as Python's textwrap.dedent does.  Lines that only contain
+// whitespace are normalized to just their newline.  Tabs and spaces are not
+// considered equal.  Dedent is the inverse of String and is useful for
+// normalizing indented raw string literals:
+//
+//	const usage = `
+//		usage: prog [flags] file
+//	`
+//	fmt.Print(indent.String("  ", indent.Dedent(usage)))
+func Dedent(s string) string {
+	common := commonIndent(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, line := range strings.SplitAfter(s, "\n") {
+		switch {
+		case isBlank(line):
+			if strings.HasSuffix(line, "\n") {
+				b.WriteByte('\n')
+			}
+		default:
+			b.WriteString(line[len(common):])
+		}
+	}
+	return b.String()
+}
+
+// DedentBytes is Dedent for a slice of bytes.  It always returns a new slice.
+func DedentBytes(b []byte) []byte {
+	return []byte(Dedent(string(b)))
+}
+
+// reindent returns s dedented, without leading or trailing blank lines, and
+// with each non-blank line started by prefix.  Blank lines are just a
+// newline.  The result ends in a newline unless it is empty.
+func reindent(prefix, s string) string {
+	lines := strings.Split(Dedent(s), "\n")
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		if line != "" {
+			b.WriteString(prefix)
+			b.WriteString(line)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
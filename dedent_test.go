@@ -0,0 +1,54 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "testing"
+
+func TestDedent(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{},
+		{in: "abc", out: "abc"},
+		{in: "  abc", out: "abc"},
+		{in: "  a\n    b\n  c\n", out: "a\n  b\nc\n"},
+		{in: "  a\n\n    b\n", out: "a\n\n  b\n"},
+		{in: "  a\n \t \n    b", out: "a\n\n  b"},
+		{in: "\ta\n\t\tb\n", out: "a\n\tb\n"},
+		// Tabs and spaces are not the same.
+		{in: "\ta\n        b\n", out: "\ta\n        b\n"},
+		{in: "  \ta\n  b\n", out: "\ta\nb\n"},
+		{in: String("    ", "a\n  b\n"), out: "a\n  b\n"},
+	} {
+		if out := Dedent(tt.in); out != tt.out {
+			t.Errorf("Dedent(%q) got %q, want %q", tt.in, out, tt.out)
+		}
+	}
+}
+
+func TestReindent(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{},
+		{in: "\n \n", out: ""},
+		{in: "abc", out: "> abc\n"},
+		{in: "\n\t a\n\n\t   b\n\n", out: "> a\n\n>   b\n"},
+	} {
+		if out := reindent("> ", tt.in); out != tt.out {
+			t.Errorf("reindent(%q) got %q, want %q", tt.in, out, tt.out)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package describe builds output in the style of "kubectl describe" using the
+// indent package to manage the nesting.  Output is made of labeled sections
+// that contain key/value fields and nested sections.  For example,
+//
+//	d := describe.New()
+//	d.Field("Name", "web-1")
+//	d.Field("Namespace", "default")
+//	labels := d.Section("Labels")
+//	labels.Field("app", "web")
+//	d.Section("Annotations")
+//	c := d.Section("Containers").Section("nginx")
+//	c.Field("Image", "nginx:1.19")
+//	c.Field("Port", 80)
+//	d.Write(os.Stdout)
+//
+// writes
+//
+//	Name:       web-1
+//	Namespace:  default
+//	Labels:
+//	  app:  web
+//	Containers:
+//	  nginx:
+//	    Image:  nginx:1.19
+//	    Port:   80
+//
+// The values of the fields of a section are aligned.  Sections that contain
+// no fields, either directly or in a nested section, such as Annotations
+// above, are not displayed.
+package describe
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pborman/indent"
+)
+
+// A Section is a labeled collection of fields and nested sections.  The
+// fields and sections are displayed in the order they are added.
+type Section struct {
+	label   string
+	entries []entry
+}
+
+// An entry is either a field or a nested section.
+type entry struct {
+	key     string
+	value   string
+	section *Section
+}
+
+// New returns a new top level section.  A top level section has no label and
+// its contents are not indented.
+func New() *Section {
+	return &Section{}
+}
+
+// Field adds the field key with value to s.  The value is formatted with
+// fmt.Sprint.  A value that contains newlines has its additional lines aligned
+// with its first line.  Field returns s.
+func (s *Section) Field(key string, value interface{}) *Section {
+	s.entries = append(s.entries, entry{key: key, value: fmt.Sprint(value)})
+	return s
+}
+
+// Fieldf adds the field key to s with a value formatted by fmt.Sprintf.
+// Fieldf returns s.
+func (s *Section) Fieldf(key, format string, v ...interface{}) *Section {
+	s.entries = append(s.entries, entry{key: key, value: fmt.Sprintf(format, v...)})
+	return s
+}
+
+// Section adds and returns a new section of s with the provided label.
+func (s *Section) Section(label string) *Section {
+	ns := &Section{label: label}
+	s.entries = append(s.entries, entry{section: ns})
+	return ns
+}
+
+// empty returns true if s contains no fields, directly or in a nested section.
+func (s *Section) empty() bool {
+	for _, e := range s.entries {
+		if e.section == nil || !e.section.empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes s to w.  The contents of a top level section are not indented
+// and the contents of each nested section are indented by two spaces.
+func (s *Section) Write(w io.Writer) error {
+	if s.label != "" {
+		if s.empty() {
+			return nil
+		}
+		if _, err := fmt.Fprintf(w, "%s:\n", s.label); err != nil {
+			return err
+		}
+		w = indent.New(w, "  ")
+	}
+	return s.writeEntries(w)
+}
+
+// writeEntries writes the entries of s to w.
+func (s *Section) writeEntries(w io.Writer) error {
+	width := 0
+	for _, e := range s.entries {
+		if e.section == nil && len(e.key) > width {
+			width = len(e.key)
+		}
+	}
+	// Values start two spaces after the longest key and its colon.
+	width += 3
+	for _, e := range s.entries {
+		if e.section != nil {
+			if err := e.section.Write(w); err != nil {
+				return err
+			}
+			continue
+		}
+		pad := strings.Repeat(" ", width-len(e.key)-1)
+		lines := strings.Split(e.value, "\n")
+		line := e.key + ":" + pad + lines[0]
+		if len(lines) > 1 {
+			line += "\n" + indent.String(strings.Repeat(" ", width), strings.Join(lines[1:], "\n"))
+		}
+		if _, err := io.WriteString(w, strings.TrimRight(line, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns s as it would be written by Write.
+func (s *Section) String() string {
+	var b strings.Builder
+	s.Write(&b)
+	return b.String()
+}
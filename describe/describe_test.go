@@ -0,0 +1,58 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package describe
+
+import "testing"
+
+func TestSection(t *testing.T) {
+	d := New()
+	d.Field("Name", "web-1")
+	d.Field("Namespace", "default")
+	labels := d.Section("Labels")
+	labels.Field("app", "web")
+	d.Section("Annotations")
+	c := d.Section("Containers").Section("nginx")
+	c.Field("Image", "nginx:1.19")
+	c.Field("Port", 80)
+	c.Section("Mounts").Section("empty")
+	d.Fieldf("Status", "%s since %d", "Running", 5)
+	d.Field("Message", "first line\nsecond line")
+	d.Field("Empty", "")
+
+	want := `Name:       web-1
+Namespace:  default
+Labels:
+  app:  web
+Containers:
+  nginx:
+    Image:  nginx:1.19
+    Port:   80
+Status:     Running since 5
+Message:    first line
+            second line
+Empty:
+`
+	if got := d.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEmptySection(t *testing.T) {
+	d := New()
+	d.Section("A").Section("B")
+	if got := d.String(); got != "" {
+		t.Errorf("got %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,301 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DocComment returns text formatted as a Go doc comment with lines no wider
+// than width columns, including the leading "// ".  The result is formatted
+// the way gofmt formats doc comments so gofmt leaves it unchanged.  Text is
+// made up of blocks separated by blank lines:
+//
+//   - A block starting with a list marker, "-", "*", "+", "•", or a number
+//     followed by "." or ")", is a list.  Each line starting with a marker of
+//     the same kind, bullet or number, as the first starts a new item.  Other
+//     lines continue the previous item.  Bullet lists are written with "-"
+//     markers, numbered lists with "." markers.  As gofmt would do, the code
+//     blocks and lists of the other kind that follow a list, up to the next
+//     paragraph, become paragraphs of its last item, and the list is then
+//     written with blank lines between its items.
+//   - A block starting with an indented line is a code block.  It continues
+//     through blank lines until a line that is not indented.  As gofmt would
+//     do, a list following a code block is made part of it.  Code blocks are
+//     dedented and then indented by a tab.
+//   - Any other block is a paragraph.  Paragraphs end at a blank line or a
+//     line starting a list.  A single line paragraph that follows another
+//     block and is followed by a paragraph is written as a heading, started
+//     by "# ", if gofmt takes it to be one.
+//
+// gofmt removes the indentation shared by all the lines of a comment, so if
+// text has no paragraphs all its lines are written without indentation.
+//
+// Paragraphs and list items are wrapped at width.  If width is not positive
+// they are not wrapped, but newlines within them are removed.  DocComment
+// returns "" if text contains no blocks.
+func DocComment(text string, width int) string {
+	if width > 0 && width < 4 {
+		width = 4
+	}
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+	var b strings.Builder
+	write := func(prefix, line string) {
+		if line == "" {
+			b.WriteString("//\n")
+			return
+		}
+		b.WriteString(prefix)
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	for len(lines) > 0 {
+		if isBlank(lines[0]) {
+			lines = lines[1:]
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("//\n")
+		}
+		var n int
+		switch _, _, ok := listMarker(lines[0]); {
+		case ok:
+			n = docList(lines, width, write)
+		case leading(lines[0]) != "":
+			n = docCode(lines, write)
+		default:
+			for n < len(lines) && !isBlank(lines[n]) {
+				if _, _, ok := listMarker(lines[n]); ok && n > 0 {
+					break
+				}
+				n++
+			}
+			for _, line := range wrap(strings.Join(lines[:n], " "), width-3) {
+				write("// ", line)
+			}
+		}
+		lines = lines[n:]
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return gofmtForm(strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n"))
+}
+
+// gofmtForm returns lines, the lines of a doc comment, with the changes
+// gofmt would make to them.  gofmt removes the indentation common to all the
+// lines, so if no text is unindented all the text is unindented.  It also
+// turns single line paragraphs that look like headings into headings.
+func gofmtForm(lines []string) string {
+	indented := true
+	for _, line := range lines {
+		if unindented(line) {
+			indented = false
+			break
+		}
+	}
+	if indented {
+		for i, line := range lines {
+			if line != "//" {
+				lines[i] = "// " + strings.TrimSpace(line[2:])
+			}
+		}
+	}
+	for i := 1; i+2 < len(lines); i++ {
+		if lines[i-1] == "//" && lines[i+1] == "//" && unindented(lines[i]) && unindented(lines[i+2]) && isHeading(lines[i][3:]) {
+			lines[i] = "// # " + lines[i][3:]
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// unindented reports whether line, a line of a doc comment, has text that is
+// not indented.
+func unindented(line string) bool {
+	return len(line) > 3 && strings.HasPrefix(line, "// ") && line[3] != ' ' && line[3] != '\t'
+}
+
+// isHeading reports whether gofmt takes line, a single line paragraph between
+// other text, to be a heading: it starts with an upper case letter, ends with
+// a letter or digit, and has no punctuation other than parentheses, commas,
+// possessive 's, and periods followed by something other than a space.
+func isHeading(line string) bool {
+	r, _ := utf8.DecodeRuneInString(line)
+	if !unicode.IsLetter(r) || !unicode.IsUpper(r) {
+		return false
+	}
+	r, _ = utf8.DecodeLastRuneInString(line)
+	if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		return false
+	}
+	if strings.ContainsAny(line, ";:!?+*/=[]{}_^°&§~%#@<\">\\") {
+		return false
+	}
+	for s := line; ; {
+		x := strings.IndexByte(s, '\'')
+		if x < 0 {
+			break
+		}
+		s = s[x+1:]
+		if s != "s" && !strings.HasPrefix(s, "s ") {
+			return false
+		}
+	}
+	for s := line; ; {
+		x := strings.IndexByte(s, '.')
+		if x < 0 {
+			break
+		}
+		s = s[x+1:]
+		if s == "" || s[0] == ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// docList writes the list starting at lines[0] as a doc comment list and
+// returns the number of lines it used.  As gofmt does, the list takes in the
+// code blocks and lists that follow it up to the next paragraph.  The items of
+// a following list of the same kind are added to the list while the other
+// blocks become paragraphs of the last item.  Such a list is loose, with its
+// items separated by blank lines.
+func docList(lines []string, width int, write func(prefix, line string)) int {
+	type item struct {
+		number string
+		text   string
+		more   []string // lines of the paragraphs after text, "" between
+	}
+	first, _, _ := listMarker(lines[0])
+	marker := func(line string) (string, string, bool) {
+		num, rest, ok := listMarker(line)
+		return num, rest, ok && (num != "") == (first != "")
+	}
+	var items []item
+	loose := false
+	n := 0
+Blocks:
+	for n < len(lines) {
+		start := n
+		for n < len(lines) && isBlank(lines[n]) {
+			n++
+		}
+		if n == len(lines) {
+			break
+		}
+		_, _, list := listMarker(lines[n])
+		if _, _, ok := marker(lines[n]); ok {
+			for ; n < len(lines) && !isBlank(lines[n]); n++ {
+				if num, rest, ok := marker(lines[n]); ok {
+					items = append(items, item{number: num, text: rest})
+					continue
+				}
+				items[len(items)-1].text += " " + lines[n]
+			}
+		} else if list || leading(lines[n]) != "" {
+			last := &items[len(items)-1]
+			last.more = append(last.more, "")
+			for ; n < len(lines) && !isBlank(lines[n]); n++ {
+				if !list && leading(lines[n]) == "" {
+					break
+				}
+				last.more = append(last.more, strings.TrimSpace(lines[n]))
+			}
+		} else {
+			n = start
+			break Blocks
+		}
+		loose = loose || start > 0
+	}
+	for i, item := range items {
+		if i > 0 && loose {
+			write("", "")
+		}
+		marker := "//   - "
+		if item.number != "" {
+			marker = "//  " + item.number + ". "
+		}
+		for j, line := range wrap(item.text, width-len(marker)) {
+			if j == 0 {
+				write(marker, line)
+			} else {
+				write("//     ", line)
+			}
+		}
+		for _, line := range item.more {
+			write("//     ", line)
+		}
+	}
+	return n
+}
+
+// docCode writes the code block starting at lines[0] as a doc comment code
+// block and returns the number of lines it used.
+func docCode(lines []string, write func(prefix, line string)) int {
+	n := 0
+	list := false // in a list following the code block
+Lines:
+	for ; n < len(lines); n++ {
+		switch line := lines[n]; {
+		case isBlank(line):
+			list = false
+		case list || leading(line) != "":
+		case isBlank(lines[n-1]):
+			// gofmt makes a list following a code block part of
+			// the code block.
+			if _, _, ok := listMarker(line); !ok {
+				break Lines
+			}
+			list = true
+		default:
+			break Lines
+		}
+	}
+	for n > 0 && isBlank(lines[n-1]) {
+		n--
+	}
+	code := Dedent(strings.Join(lines[:n], "\n"))
+	for _, line := range strings.Split(code, "\n") {
+		write("//\t", strings.TrimRight(line, " \t"))
+	}
+	return n
+}
+
+// listMarker returns the number of the list item started by line and the rest
+// of line.  The number is "" for bullet items.  The returned bool is false if
+// line does not start a list item.
+func listMarker(line string) (number, rest string, ok bool) {
+	s := strings.TrimLeft(line, " \t")
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	switch {
+	case i > 0 && i < len(s) && (s[i] == '.' || s[i] == ')'):
+		number, s = s[:i], s[i+1:]
+	case strings.HasPrefix(s, "•"):
+		s = s[len("•"):]
+	case i == 0 && s != "" && strings.IndexByte("-*+", s[0]) >= 0:
+		s = s[1:]
+	default:
+		return "", "", false
+	}
+	if s == "" || (s[0] != ' ' && s[0] != '\t') {
+		return "", "", false
+	}
+	return number, strings.TrimSpace(s), true
+}
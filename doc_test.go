@@ -0,0 +1,167 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"go/format"
+	"testing"
+)
+
+func TestDocComment(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		in    string
+		width int
+		out   string
+	}{{
+		name: "empty",
+		in:   "\n\n",
+		out:  "",
+	}, {
+		name:  "paragraphs",
+		in:    "The quick brown fox jumps over the lazy dog.\n\n\nSecond\nparagraph.",
+		width: 25,
+		out: "" +
+			"// The quick brown fox\n" +
+			"// jumps over the lazy\n" +
+			"// dog.\n" +
+			"//\n" +
+			"// Second paragraph.\n",
+	}, {
+		name:  "list",
+		in:    "Options:\n- one is the first option\n* two\n  continued\n3. three",
+		width: 20,
+		out: "" +
+			"// Options:\n" +
+			"//\n" +
+			"//   - one is the\n" +
+			"//     first option\n" +
+			"//   - two continued\n" +
+			"//     3. three\n",
+	}, {
+		name: "code",
+		in:   "Example:\n\n    x := 1\n\n      y := 2\n\n\nDone.",
+		out: "" +
+			"// Example:\n" +
+			"//\n" +
+			"//\tx := 1\n" +
+			"//\n" +
+			"//\t  y := 2\n" +
+			"//\n" +
+			"// Done.\n",
+	}, {
+		name: "not a list",
+		in:   "-1 is negative\n\n2.5 is not a number",
+		out: "" +
+			"// -1 is negative\n" +
+			"//\n" +
+			"// 2.5 is not a number\n",
+	}, {
+		name: "long numbered list",
+		in:   "Steps:\n1. a\n2. b\n3. c\n4. d\n5. e\n6. f\n7. g\n8. h\n9. i\n10. j\n",
+		out: "" +
+			"// Steps:\n" +
+			"//\n" +
+			"//  1. a\n" +
+			"//  2. b\n" +
+			"//  3. c\n" +
+			"//  4. d\n" +
+			"//  5. e\n" +
+			"//  6. f\n" +
+			"//  7. g\n" +
+			"//  8. h\n" +
+			"//  9. i\n" +
+			"//  10. j\n",
+	}, {
+		name: "code then list",
+		in:   "A\n\n  x := 1\n\n- item\n",
+		out: "" +
+			"// A\n" +
+			"//\n" +
+			"//\t  x := 1\n" +
+			"//\n" +
+			"//\t- item\n",
+	}, {
+		name: "only a list",
+		in:   "- a\n- b\n",
+		out: "" +
+			"// - a\n" +
+			"// - b\n",
+	}, {
+		name: "only code",
+		in:   "  x := 1\n  y := 2\n",
+		out: "" +
+			"// x := 1\n" +
+			"// y := 2\n",
+	}, {
+		name: "list then code",
+		in:   "A\n\n- a\n- b\n\n  x := 1\n    y := 2\n\nB\n",
+		out: "" +
+			"// A\n" +
+			"//\n" +
+			"//   - a\n" +
+			"//\n" +
+			"//   - b\n" +
+			"//\n" +
+			"//     x := 1\n" +
+			"//     y := 2\n" +
+			"//\n" +
+			"// B\n",
+	}, {
+		name: "bullets then numbers",
+		in:   "A\n\n- a\n\n1. b\n2. c\n",
+		out: "" +
+			"// A\n" +
+			"//\n" +
+			"//   - a\n" +
+			"//\n" +
+			"//     1. b\n" +
+			"//     2. c\n",
+	}, {
+		name: "lists of one kind",
+		in:   "A\n\n- a\n- b\n\n- c\n",
+		out: "" +
+			"// A\n" +
+			"//\n" +
+			"//   - a\n" +
+			"//\n" +
+			"//   - b\n" +
+			"//\n" +
+			"//   - c\n",
+	}, {
+		name: "heading",
+		in:   "A.\n\nThe Heading\n\nB.\n\nNot a heading.\n\nC.\n",
+		out: "" +
+			"// A.\n" +
+			"//\n" +
+			"// # The Heading\n" +
+			"//\n" +
+			"// B.\n" +
+			"//\n" +
+			"// Not a heading.\n" +
+			"//\n" +
+			"// C.\n",
+	}} {
+		if got := DocComment(tt.in, tt.width); got != tt.out {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.name, got, tt.out)
+		}
+		src := tt.out + "package p\n"
+		if out, err := format.Source([]byte(src)); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if string(out) != src {
+			t.Errorf("%s: gofmt changed:\n%s\nto:\n%s", tt.name, src, out)
+		}
+	}
+}
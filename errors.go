@@ -0,0 +1,108 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrorTree returns err and the errors it wraps as an indented tree.  Each
+// error is on its own line and the errors it wraps, as returned by its Unwrap
+// method, follow on lines indented by one more prefix.  The errors of an error
+// with an Unwrap() []error method, such as one returned by errors.Join, are
+// peers.
+//
+// Most wrapping errors include the message of the error they wrap.  If dedup is
+// true the messages of the wrapped errors are removed from the end of the
+// wrapper's message, along with any separating ": ".  An error whose message
+// is then empty, such as the result of errors.Join, is not displayed and its
+// errors take its place in the tree.  For example, with dedup set the error
+// returned by
+//
+//	fmt.Errorf("loading config: %w", fmt.Errorf("open config.json: %w", err))
+//
+// is displayed as
+//
+//	loading config
+//	  open config.json
+//	    permission denied
+func ErrorTree(err error, prefix string, dedup bool) string {
+	if err == nil {
+		return ""
+	}
+	var b strings.Builder
+	writeErrorTree(&b, err, prefix, dedup)
+	return b.String()
+}
+
+// writeErrorTree writes the tree of err to w.
+func writeErrorTree(w io.Writer, err error, prefix string, dedup bool) {
+	children := unwrapErrors(err)
+	msg := err.Error()
+	if dedup {
+		msg = trimWrapped(msg, children)
+	}
+	if msg != "" {
+		io.WriteString(w, msg)
+		io.WriteString(w, "\n")
+		w = New(w, prefix)
+	}
+	for _, c := range children {
+		writeErrorTree(w, c, prefix, dedup)
+	}
+}
+
+// unwrapErrors returns the non-nil errors wrapped by err.
+func unwrapErrors(err error) []error {
+	var errs []error
+	if u, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = u.Unwrap()
+	} else if e := errors.Unwrap(err); e != nil {
+		errs = []error{e}
+	}
+	var nonNil []error
+	for _, e := range errs {
+		if e != nil {
+			nonNil = append(nonNil, e)
+		}
+	}
+	return nonNil
+}
+
+// trimWrapped returns msg with the messages of the wrapped errors removed from
+// its end.
+func trimWrapped(msg string, wrapped []error) string {
+	switch len(wrapped) {
+	case 0:
+		return msg
+	case 1:
+		if !strings.HasSuffix(msg, wrapped[0].Error()) {
+			return msg
+		}
+		msg = strings.TrimSuffix(msg, wrapped[0].Error())
+	default:
+		msgs := make([]string, len(wrapped))
+		for i, e := range wrapped {
+			msgs[i] = e.Error()
+		}
+		if msg != strings.Join(msgs, "\n") {
+			return msg
+		}
+		return ""
+	}
+	return strings.TrimRight(strings.TrimSuffix(strings.TrimRight(msg, " "), ":"), " ")
+}
@@ -0,0 +1,96 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// joinError is errors.Join, which is not available in older versions of Go.
+type joinError []error
+
+func (j joinError) Error() string {
+	msgs := make([]string, len(j))
+	for i, err := range j {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (j joinError) Unwrap() []error { return j }
+
+func TestErrorTree(t *testing.T) {
+	denied := errors.New("permission denied")
+	chain := fmt.Errorf("loading config: %w", fmt.Errorf("open config.json: %w", denied))
+	for _, tt := range []struct {
+		name  string
+		err   error
+		dedup bool
+		out   string
+	}{{
+		name: "nil",
+	}, {
+		name: "single",
+		err:  denied,
+		out:  "permission denied\n",
+	}, {
+		name:  "chain",
+		err:   chain,
+		dedup: true,
+		out: "" +
+			"loading config\n" +
+			"  open config.json\n" +
+			"    permission denied\n",
+	}, {
+		name: "chain without dedup",
+		err:  chain,
+		out: "" +
+			"loading config: open config.json: permission denied\n" +
+			"  open config.json: permission denied\n" +
+			"    permission denied\n",
+	}, {
+		name:  "not a suffix",
+		err:   fmt.Errorf("%w (while loading)", denied),
+		dedup: true,
+		out: "" +
+			"permission denied (while loading)\n" +
+			"  permission denied\n",
+	}, {
+		name:  "same message",
+		err:   fmt.Errorf("%w", denied),
+		dedup: true,
+		out:   "permission denied\n",
+	}, {
+		name: "join",
+		err: fmt.Errorf("setup: %w", joinError{
+			chain,
+			errors.New("no network"),
+		}),
+		dedup: true,
+		out: "" +
+			"setup\n" +
+			"  loading config\n" +
+			"    open config.json\n" +
+			"      permission denied\n" +
+			"  no network\n",
+	}} {
+		if got := ErrorTree(tt.err, "  ", tt.dedup); got != tt.out {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.name, got, tt.out)
+		}
+	}
+}
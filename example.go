@@ -0,0 +1,101 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ExampleOutput returns output formatted as the "// Output:" comment that ends
+// an example function in a test file.  If unordered is true the comment starts
+// with "// Unordered output:" instead.  Each line of output is commented with
+// "// " and has its trailing whitespace removed, as gofmt would remove it.
+// Blank lines are written as "//".  Leading and trailing blank lines are
+// removed as they are ignored when the output is compared.  The result ends
+// in a newline.
+func ExampleOutput(output string, unordered bool) string {
+	var b strings.Builder
+	if unordered {
+		b.WriteString("// Unordered output:\n")
+	} else {
+		b.WriteString("// Output:\n")
+	}
+	output = strings.Trim(strings.Replace(output, "\r\n", "\n", -1), "\n")
+	if isBlank(output) {
+		return b.String()
+	}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			b.WriteString("//\n")
+			continue
+		}
+		b.WriteString("// ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// outputRE matches the line that starts an example's output comment.  It is
+// the pattern used by go/doc.
+var outputRE = regexp.MustCompile(`(?i)^[[:space:]]*(unordered )?output:`)
+
+// ParseExampleOutput returns the expected output from src, which contains an
+// example function or its final comment.  The output is the text of the line
+// comments following the last "// Output:" or "// Unordered output:" line, up
+// to the first line that is not a line comment.  Text following the colon on
+// the same line is the first line of output.  As when examples are run, the
+// output is trimmed of leading and trailing whitespace.  Unordered reports
+// whether the output is unordered and ok reports whether an output comment was
+// found.
+func ParseExampleOutput(src string) (output string, unordered, ok bool) {
+	lines := strings.Split(strings.Replace(src, "\r\n", "\n", -1), "\n")
+	start := -1
+	for i, line := range lines {
+		text, isComment := lineComment(line)
+		if !isComment {
+			continue
+		}
+		if m := outputRE.FindStringSubmatch(text); m != nil {
+			start = i
+			unordered = m[1] != ""
+			lines[i] = "//" + text[len(m[0]):]
+		}
+	}
+	if start < 0 {
+		return "", false, false
+	}
+	var out []string
+	for _, line := range lines[start:] {
+		text, isComment := lineComment(line)
+		if !isComment {
+			break
+		}
+		out = append(out, strings.TrimPrefix(text, " "))
+	}
+	return strings.TrimSpace(strings.Join(out, "\n")), unordered, true
+}
+
+// lineComment returns the text of line following "//" and true if line is a
+// line comment.
+func lineComment(line string) (string, bool) {
+	line = strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(line, "//") {
+		return "", false
+	}
+	return line[2:], true
+}
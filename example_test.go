@@ -0,0 +1,74 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "testing"
+
+func TestExampleOutput(t *testing.T) {
+	for _, tt := range []struct {
+		in        string
+		unordered bool
+		out       string
+	}{
+		{in: "", out: "// Output:\n"},
+		{in: "a\n", out: "// Output:\n// a\n"},
+		{in: "\na  \n\n\tb\n\n", out: "// Output:\n// a\n//\n// \tb\n"},
+		{in: "x\r\ny", unordered: true, out: "// Unordered output:\n// x\n// y\n"},
+	} {
+		if got := ExampleOutput(tt.in, tt.unordered); got != tt.out {
+			t.Errorf("ExampleOutput(%q, %v) got %q, want %q", tt.in, tt.unordered, got, tt.out)
+		}
+	}
+}
+
+func TestParseExampleOutput(t *testing.T) {
+	for _, tt := range []struct {
+		in        string
+		out       string
+		unordered bool
+		ok        bool
+	}{
+		{in: "func Example() {\n}\n"},
+		{
+			in:  "func Example() {\n\tfmt.Println(\"a\")\n\t// Output:\n\t// a\n\t//\n\t//   b\n}\n",
+			out: "a\n\n  b",
+			ok:  true,
+		},
+		{
+			in:  "\t// Output: hello\n",
+			out: "hello",
+			ok:  true,
+		},
+		{
+			in:        "// output: ignored\nx()\n// Unordered output:\n// b\n// a\n",
+			out:       "b\na",
+			unordered: true,
+			ok:        true,
+		},
+	} {
+		out, unordered, ok := ParseExampleOutput(tt.in)
+		if out != tt.out || unordered != tt.unordered || ok != tt.ok {
+			t.Errorf("ParseExampleOutput(%q) got %q, %v, %v, want %q, %v, %v", tt.in, out, unordered, ok, tt.out, tt.unordered, tt.ok)
+		}
+	}
+}
+
+func TestExampleOutputRoundTrip(t *testing.T) {
+	in := "line 1\n  line 2\n\nline 4"
+	got, _, ok := ParseExampleOutput(ExampleOutput(in, false))
+	if !ok || got != in {
+		t.Errorf("got %q, %v, want %q", got, ok, in)
+	}
+}
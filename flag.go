@@ -0,0 +1,111 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// PrintFlagDefaults writes the usage of all the flags in fs to w in the style
+// of flag.PrintDefaults.  Each flag is introduced by a line starting with
+// indent and its description follows, wrapped to width columns, on lines
+// starting with hang.  The width includes hang.  If width is not positive the
+// descriptions are not wrapped.  Newlines in a flag's usage start new lines.
+// For example, with an indent of "", a hang of "    ", and a width of 28:
+//
+//	-name string
+//	    the name of the thing
+//	    being named (default
+//	    "bob")
+func PrintFlagDefaults(w io.Writer, fs *flag.FlagSet, indent, hang string, width int) {
+	if width > 0 {
+		width -= len(hang)
+		if width < 1 {
+			width = 1
+		}
+	}
+	hw := New(w, hang)
+	fs.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+		fmt.Fprintf(w, "%s-%s", indent, f.Name)
+		if name != "" {
+			fmt.Fprintf(w, " %s", name)
+		}
+		fmt.Fprintln(w)
+		if !isZeroFlag(f) {
+			if isStringFlag(f) {
+				usage += fmt.Sprintf(" (default %q)", f.DefValue)
+			} else {
+				usage += fmt.Sprintf(" (default %v)", f.DefValue)
+			}
+		}
+		for _, line := range wrap(usage, width) {
+			fmt.Fprintln(hw, line)
+		}
+	})
+}
+
+// FlagUsage returns a function, suitable for use as the Usage field of fs, that
+// writes "Usage of name:" to fs's output followed by the defaults of fs as
+// written by PrintFlagDefaults.
+func FlagUsage(fs *flag.FlagSet, indent, hang string, width int) func() {
+	return func() {
+		w := fs.Output()
+		if fs.Name() == "" {
+			fmt.Fprintf(w, "Usage:\n")
+		} else {
+			fmt.Fprintf(w, "Usage of %s:\n", fs.Name())
+		}
+		PrintFlagDefaults(w, fs, indent, hang, width)
+	}
+}
+
+// isZeroFlag reports whether f's default value is the zero value of its type.
+// It is the same test flag.PrintDefaults uses to decide whether to display the
+// default value.
+func isZeroFlag(f *flag.Flag) (zero bool) {
+	switch f.DefValue {
+	case "", "0", "false":
+		return true
+	}
+	typ := reflect.TypeOf(f.Value)
+	var z reflect.Value
+	if typ.Kind() == reflect.Ptr {
+		z = reflect.New(typ.Elem())
+	} else {
+		z = reflect.Zero(typ)
+	}
+	// The String method of a custom Value may not handle its zero value.
+	defer func() {
+		if recover() != nil {
+			zero = false
+		}
+	}()
+	return f.DefValue == z.Interface().(flag.Value).String()
+}
+
+// isStringFlag reports whether f was created by the String or StringVar
+// functions of the flag package, in which case its default is displayed
+// quoted.
+func isStringFlag(f *flag.Flag) bool {
+	typ := reflect.TypeOf(f.Value)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.String && typ.PkgPath() == "flag"
+}
@@ -0,0 +1,79 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestPrintFlagDefaults(t *testing.T) {
+	fs := flag.NewFlagSet("prog", flag.ContinueOnError)
+	fs.String("name", "bob", "the name of the thing being named")
+	fs.Bool("v", false, "be verbose")
+	fs.Int("n", 3, "the `count` of things")
+	fs.Duration("timeout", time.Second, "how long to wait\nbefore giving up")
+
+	var buf bytes.Buffer
+	PrintFlagDefaults(&buf, fs, "  ", "      ", 30)
+	want := `  -n count
+      the count of things
+      (default 3)
+  -name string
+      the name of the thing
+      being named (default
+      "bob")
+  -timeout duration
+      how long to wait
+      before giving up
+      (default 1s)
+  -v
+      be verbose
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	PrintFlagDefaults(&buf, fs, "", "\t", 0)
+	want = `-n count
+	the count of things (default 3)
+-name string
+	the name of the thing being named (default "bob")
+-timeout duration
+	how long to wait
+	before giving up (default 1s)
+-v
+	be verbose
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFlagUsage(t *testing.T) {
+	var buf bytes.Buffer
+	fs := flag.NewFlagSet("prog", flag.ContinueOnError)
+	fs.SetOutput(&buf)
+	fs.Bool("v", false, "be verbose")
+	fs.Usage = FlagUsage(fs, " ", "   ", 80)
+	fs.Parse([]string{"-x"})
+	want := "flag provided but not defined: -x\nUsage of prog:\n -v\n   be verbose\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+)
+
+// NewHanging returns a writer that prefixes the first line written to it with
+// first and the remaining lines with rest, and writes the results to w.  This
+// produces a hanging indent, such as a list item or a YAML sequence entry,
+// when first ends with a marker and rest is as wide as first:
+//
+//	w := indent.NewHanging(os.Stdout, "  - ", "    ")
+//
+// If the writer is created part way through a line, the rest of that line is
+// not prefixed and the next line is the first line.  As with New, NewHanging
+// is intelligent about nesting.  Both first and rest are appended to the
+// prefix of w when w was returned by New.
+func NewHanging(w io.Writer, first, rest string, opts ...Option) io.Writer {
+	if first == rest {
+		return New(w, rest, opts...)
+	}
+	return New(w, rest, append([]Option{withFirst(first)}, opts...)...)
+}
+
+// withFirst sets the prefix of the first line written by the writer being
+// created to first, appended to the prefix of the writer it nests in.
+func withFirst(first string) Option {
+	return func(in *indenter) {
+		var p []byte
+		if in.p != nil {
+			p = in.p.prefix
+		}
+		in.opts().first = append(p[:len(p):len(p)], first...)
+	}
+}
+
+// hangingWrite writes buf using in.opt.first as the prefix of the first line it
+// starts.  Once the first line has been started in.opt.first is cleared.
+func (in *indenter) hangingWrite(buf []byte) (int, error) {
+	n := 0
+	if !in.s.sol {
+		x := bytes.IndexByte(buf, '\n')
+		if x < 0 {
+			return in.indentWrite(buf)
+		}
+		m, err := in.indentWrite(buf[:x+1])
+		n += m
+		if err != nil || m <= x {
+			return n, err
+		}
+		buf = buf[x+1:]
+	}
+	if len(buf) == 0 {
+		return n, nil
+	}
+	// The newline ending the first line is written with the rest prefix
+	// so that options that look ahead to the next line, such as
+	// WithEagerPrefix, see the right prefix.
+	line := buf
+	switch x := bytes.IndexByte(buf, '\n'); {
+	case x == 0:
+		line = buf[:1]
+	case x > 0:
+		line = buf[:x]
+	}
+	rest := in.prefix
+	in.setPrefix(in.opt.first)
+	m, err := in.indentWrite(line)
+	in.setPrefix(rest)
+	n += m
+	if m > 0 {
+		in.opt.first = nil
+	}
+	if err != nil || m < len(line) {
+		return n, err
+	}
+	m, err = in.indentWrite(buf[len(line):])
+	return n + m, err
+}
@@ -0,0 +1,119 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestNewHanging(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		first, rest string
+		opts        []Option
+		in          []string
+		out         string
+	}{
+		{
+			name:  "simple",
+			first: "  - ",
+			rest:  "    ",
+			in:    []string{"one\ntwo\nthree\n"},
+			out:   "  - one\n    two\n    three\n",
+		},
+		{
+			name:  "pieces",
+			first: "- ",
+			rest:  "  ",
+			in:    []string{"o", "ne", "\n", "two", "\n"},
+			out:   "- one\n  two\n",
+		},
+		{
+			name:  "blank first line",
+			first: "- ",
+			rest:  "  ",
+			in:    []string{"\none\n"},
+			out:   "- \n  one\n",
+		},
+		{
+			name:  "same",
+			first: "> ",
+			rest:  "> ",
+			in:    []string{"a\nb\n"},
+			out:   "> a\n> b\n",
+		},
+		{
+			name:  "eager",
+			first: "- ",
+			rest:  "  ",
+			opts:  []Option{WithEagerPrefix()},
+			in:    []string{"a\nb\n"},
+			out:   "- a\n  b\n  ",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewHanging(&buf, tt.first, tt.rest, tt.opts...)
+			for _, s := range tt.in {
+				if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+					t.Fatalf("Write(%q) got %d, %v", s, n, err)
+				}
+			}
+			if got := buf.String(); got != tt.out {
+				t.Errorf("got %q, want %q", got, tt.out)
+			}
+		})
+	}
+}
+
+func TestNewHangingNested(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "> ")
+	io.WriteString(w, "list:\n")
+	for _, item := range []string{"a\nb\n", "c\n"} {
+		io.WriteString(NewHanging(w, "- ", "  "), item)
+	}
+	io.WriteString(w, "end\n")
+	if got, want := buf.String(), "> list:\n> - a\n>   b\n> - c\n> end\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A writer created part way through a line does not prefix that line.
+	buf.Reset()
+	w = New(&buf, "> ")
+	io.WriteString(w, "key: ")
+	io.WriteString(NewHanging(w, "- ", "  "), "a\nb\nc\n")
+	if got, want := buf.String(), "> key: a\n> - b\n>   c\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewHangingShort(t *testing.T) {
+	fw := &fakeWriter{left: 3}
+	w := NewHanging(fw, "- ", "  ")
+	if n, err := io.WriteString(w, "abc\ndef\n"); n != 1 || err != io.EOF {
+		t.Errorf("got %d, %v, want 1, %v", n, err, io.EOF)
+	}
+	// The first line has been started so the rest use the rest prefix.
+	fw.left = 100
+	if n, err := io.WriteString(w, "bc\ndef\n"); n != 7 || err != nil {
+		t.Errorf("got %d, %v, want 7, nil", n, err)
+	}
+	if got, want := fw.buf.String(), "- abc\n  def\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
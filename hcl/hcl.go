@@ -0,0 +1,246 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package hcl builds HCL, the configuration language used by Terraform, using
+// the indent package to manage the nesting of blocks.  For example,
+//
+//	f := hcl.NewBody()
+//	r := f.Block("resource", "aws_instance", "web")
+//	r.Attr("ami", "ami-123456")
+//	r.Attr("instance_type", "t2.micro")
+//	r.Attr("subnet_id", hcl.Expr("aws_subnet.main.id"))
+//	r.Block("tags").Attr("Name", "web")
+//	f.Write(os.Stdout)
+//
+// writes
+//
+//	resource "aws_instance" "web" {
+//	  ami           = "ami-123456"
+//	  instance_type = "t2.micro"
+//	  subnet_id     = aws_subnet.main.id
+//
+//	  tags {
+//	    Name = "web"
+//	  }
+//	}
+//
+// As terraform fmt does, the equal signs of consecutive attributes are
+// aligned.  Blocks are separated from their neighbors by a blank line.
+package hcl
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pborman/indent"
+)
+
+// An Expr is an HCL expression, such as a reference like var.name, that is
+// written as is.
+type Expr string
+
+// A Body is the contents of an HCL file or block: attributes and nested
+// blocks, written in the order they are added.
+type Body struct {
+	items []item
+}
+
+// An item is either an attribute or a block.
+type item struct {
+	name   string
+	value  string // the formatted value of an attribute
+	block  bool
+	labels []string
+	body   *Body
+}
+
+// NewBody returns an empty Body, such as the body of a file.
+func NewBody() *Body {
+	return &Body{}
+}
+
+// Attr adds the attribute name with value to b and returns b.  The value is
+// formatted as an HCL value: an Expr is used as is, a string is quoted, a
+// bool or a number is formatted as a literal, and a slice is formatted as a
+// tuple and a map with string keys as an object, each with their elements
+// formatted the same way.  Other values are formatted with fmt.Sprint and
+// quoted.
+func (b *Body) Attr(name string, value interface{}) *Body {
+	b.items = append(b.items, item{name: name, value: Value(value)})
+	return b
+}
+
+// Block adds a block of type typ with labels to b and returns the body of the
+// new block.
+func (b *Body) Block(typ string, labels ...string) *Body {
+	nb := &Body{}
+	b.items = append(b.items, item{name: typ, block: true, labels: labels, body: nb})
+	return nb
+}
+
+// Write writes b to w.  The blocks in b are indented by two spaces for each
+// level of nesting.
+func (b *Body) Write(w io.Writer) error {
+	return b.write(w, 0)
+}
+
+// write writes b to w, which is depth levels of indenters deep.
+func (b *Body) write(w io.Writer, depth int) error {
+	for i, it := range b.items {
+		if i > 0 && (it.block || b.items[i-1].block) {
+			// Write blank lines below the indenters so they are
+			// not indented.
+			if _, err := io.WriteString(indent.Unwrap(w, depth), "\n"); err != nil {
+				return err
+			}
+		}
+		if !it.block {
+			// Align the equal signs of this run of attributes.
+			width := b.width(i)
+			if _, err := fmt.Fprintf(w, "%-*s = %s\n", width, it.name, it.value); err != nil {
+				return err
+			}
+			continue
+		}
+		line := it.name
+		for _, l := range it.labels {
+			line += " " + quote(l)
+		}
+		if len(it.body.items) == 0 {
+			if _, err := fmt.Fprintf(w, "%s {}\n", line); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s {\n", line); err != nil {
+			return err
+		}
+		if err := it.body.write(indent.New(w, "  "), depth+1); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "}\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// width returns the width of the longest name in the run of attributes that
+// contains the attribute at index i.
+func (b *Body) width(i int) int {
+	for i > 0 && !b.items[i-1].block {
+		i--
+	}
+	width := 0
+	for _, a := range b.items[i:] {
+		if a.block {
+			break
+		}
+		if len(a.name) > width {
+			width = len(a.name)
+		}
+	}
+	return width
+}
+
+// String returns b as it would be written by Write.
+func (b *Body) String() string {
+	var sb strings.Builder
+	b.Write(&sb)
+	return sb.String()
+}
+
+// Value returns v formatted as an HCL value as described by Body.Attr.
+func Value(v interface{}) string {
+	switch v := v.(type) {
+	case Expr:
+		return string(v)
+	case string:
+		return quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []string:
+		vals := make([]string, len(v))
+		for i, s := range v {
+			vals[i] = quote(s)
+		}
+		return "[" + strings.Join(vals, ", ") + "]"
+	case []interface{}:
+		vals := make([]string, len(v))
+		for i, e := range v {
+			vals[i] = Value(e)
+		}
+		return "[" + strings.Join(vals, ", ") + "]"
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = e
+		}
+		return Value(m)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		vals := make([]string, len(keys))
+		for i, k := range keys {
+			vals[i] = quote(k) + " = " + Value(v[k])
+		}
+		return "{ " + strings.Join(vals, ", ") + " }"
+	}
+	return quote(fmt.Sprint(v))
+}
+
+// quote returns s as a quoted HCL string.  Template sequences are escaped so
+// s is taken literally.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			// Double the $ or % so ${ and %{ do not start a template.
+			b.WriteRune(r)
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,83 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package hcl
+
+import "testing"
+
+func TestBody(t *testing.T) {
+	f := NewBody()
+	f.Attr("region", "us-east-1")
+	r := f.Block("resource", "aws_instance", "web")
+	r.Attr("ami", "ami-123456")
+	r.Attr("instance_type", "t2.micro")
+	r.Attr("subnet_id", Expr("aws_subnet.main.id"))
+	r.Block("tags").Attr("Name", "web")
+	r.Attr("count", 2)
+	r.Attr("ebs_optimized", true)
+	r.Block("lifecycle")
+	f.Block("terraform").Block("backend", "s3").Attr("bucket", "b")
+
+	want := `region = "us-east-1"
+
+resource "aws_instance" "web" {
+  ami           = "ami-123456"
+  instance_type = "t2.micro"
+  subnet_id     = aws_subnet.main.id
+
+  tags {
+    Name = "web"
+  }
+
+  count         = 2
+  ebs_optimized = true
+
+  lifecycle {}
+}
+
+terraform {
+  backend "s3" {
+    bucket = "b"
+  }
+}
+`
+	if got := f.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestValue(t *testing.T) {
+	for _, tt := range []struct {
+		in  interface{}
+		out string
+	}{
+		{in: "a", out: `"a"`},
+		{in: "a \"b\" \\ \n\t\x01", out: `"a \"b\" \\ \n\t\u0001"`},
+		{in: "${var.x} %{if} $5 100%", out: `"$${var.x} %%{if} $5 100%"`},
+		{in: "héllo", out: `"héllo"`},
+		{in: Expr("var.x"), out: "var.x"},
+		{in: true, out: "true"},
+		{in: 42, out: "42"},
+		{in: 1.5, out: "1.5"},
+		{in: []string{"a", "b"}, out: `["a", "b"]`},
+		{in: []interface{}{1, "a", Expr("local.b")}, out: `[1, "a", local.b]`},
+		{in: map[string]string{"b": "2", "a": "1"}, out: `{ "a" = "1", "b" = "2" }`},
+		{in: map[string]interface{}{}, out: "{}"},
+		{in: struct{}{}, out: `"{}"`},
+	} {
+		if got := Value(tt.in); got != tt.out {
+			t.Errorf("Value(%#v) got %s, want %s", tt.in, got, tt.out)
+		}
+	}
+}
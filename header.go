@@ -0,0 +1,133 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// CommentPrefixes maps file name extensions to the text that starts a line
+// comment in the language of the file.  It is used by CommentPrefix.
+var CommentPrefixes = map[string]string{
+	".c":     "//",
+	".cc":    "//",
+	".cpp":   "//",
+	".go":    "//",
+	".h":     "//",
+	".java":  "//",
+	".js":    "//",
+	".proto": "//",
+	".rs":    "//",
+	".swift": "//",
+	".ts":    "//",
+	".py":    "#",
+	".rb":    "#",
+	".sh":    "#",
+	".pl":    "#",
+	".tf":    "#",
+	".toml":  "#",
+	".yaml":  "#",
+	".yml":   "#",
+	".sql":   "--",
+	".lua":   "--",
+	".hs":    "--",
+	".el":    ";;",
+	".lisp":  ";;",
+	".vim":   "\"",
+}
+
+// CommentPrefix returns the line comment prefix for the language of the file
+// named name, as determined by its extension, and whether it is known.
+// Makefiles and Dockerfiles, which have no extension, use "#".
+func CommentPrefix(name string) (string, bool) {
+	switch filepath.Base(name) {
+	case "Makefile", "makefile", "GNUmakefile", "Dockerfile":
+		return "#", true
+	}
+	prefix, ok := CommentPrefixes[strings.ToLower(filepath.Ext(name))]
+	return prefix, ok
+}
+
+// ApplyHeader returns src with header, such as a license, at its start as
+// line comments started by comment.  Each non-blank line of header is started
+// with comment and a space and each blank line with just comment.  The header
+// is followed by a blank line.  An existing header, a run of comment lines at
+// the start of src that mention a copyright or an SPDX-License-Identifier and
+// are followed by a blank line, is replaced so applying the same header again
+// does not change src.  A "#!" line at the start of src is kept before the
+// header.
+func ApplyHeader(src, header, comment string) string {
+	var b strings.Builder
+	if strings.HasPrefix(src, "#!") {
+		x := strings.IndexByte(src, '\n') + 1
+		if x == 0 {
+			x = len(src)
+		}
+		b.WriteString(src[:x])
+		src = src[x:]
+	}
+	src = stripHeader(src, comment)
+
+	header = strings.TrimRight(header, "\n")
+	for _, line := range strings.Split(header, "\n") {
+		b.WriteString(comment)
+		if !isBlank(line) {
+			b.WriteByte(' ')
+			b.WriteString(strings.TrimRight(line, " \t\r"))
+		}
+		b.WriteByte('\n')
+	}
+	if src != "" {
+		b.WriteByte('\n')
+		b.WriteString(src)
+	}
+	return b.String()
+}
+
+// stripHeader returns src without the header at its start, if any, and the
+// blank lines that follow it.
+func stripHeader(src, comment string) string {
+	rest := src
+	var block strings.Builder
+	for rest != "" {
+		line := rest
+		if x := strings.IndexByte(rest, '\n'); x >= 0 {
+			line = rest[:x+1]
+		}
+		if !strings.HasPrefix(line, comment) {
+			break
+		}
+		block.WriteString(line)
+		rest = rest[len(line):]
+	}
+	text := block.String()
+	if !strings.Contains(strings.ToLower(text), "copyright") && !strings.Contains(text, "SPDX-License-Identifier") {
+		return src
+	}
+	// A comment attached to what follows it, such as a package doc
+	// comment, is not a header.
+	if x := strings.IndexByte(rest, '\n'); rest != "" && (x < 0 || !isBlank(rest[:x])) {
+		return src
+	}
+	for rest != "" {
+		x := strings.IndexByte(rest, '\n')
+		if x < 0 || !isBlank(rest[:x]) {
+			break
+		}
+		rest = rest[x+1:]
+	}
+	return rest
+}
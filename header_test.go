@@ -0,0 +1,119 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "testing"
+
+const testHeader = `  Copyright 2020 Paul Borman
+
+  Licensed under the Apache License, Version 2.0.
+`
+
+const testHeaded = `//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0.
+`
+
+func TestApplyHeader(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		src     string
+		comment string
+		header  string
+		want    string
+	}{
+		{
+			name:    "empty",
+			comment: "//",
+			want:    testHeaded,
+		},
+		{
+			name:    "new",
+			src:     "package x\n",
+			comment: "//",
+			want:    testHeaded + "\npackage x\n",
+		},
+		{
+			name:    "doc comment",
+			src:     "// Package x does things.\npackage x\n",
+			comment: "//",
+			want:    testHeaded + "\n// Package x does things.\npackage x\n",
+		},
+		{
+			name:    "replace",
+			src:     "// Copyright 2019 Someone\n// All rights reserved.\n\n\npackage x\n",
+			comment: "//",
+			want:    testHeaded + "\npackage x\n",
+		},
+		{
+			name:    "applied",
+			src:     testHeaded + "\npackage x\n",
+			comment: "//",
+			want:    testHeaded + "\npackage x\n",
+		},
+		{
+			name:    "spdx",
+			src:     "// SPDX-License-Identifier: MIT\n\npackage x\n",
+			comment: "//",
+			want:    testHeaded + "\npackage x\n",
+		},
+		{
+			name:    "license doc comment",
+			src:     "// Package license parses SPDX license identifiers.\npackage license\n",
+			comment: "//",
+			want:    testHeaded + "\n// Package license parses SPDX license identifiers.\npackage license\n",
+		},
+		{
+			name:    "attached copyright",
+			src:     "// Copyright returns the copyright notice.\nfunc Copyright() string\n",
+			comment: "//",
+			want:    testHeaded + "\n// Copyright returns the copyright notice.\nfunc Copyright() string\n",
+		},
+		{
+			name:    "shebang",
+			src:     "#!/bin/sh\n# Copyright 2019 Someone\n\necho hi\n",
+			comment: "#",
+			header:  "Copyright 2020 Paul Borman\n",
+			want:    "#!/bin/sh\n# Copyright 2020 Paul Borman\n\necho hi\n",
+		},
+	} {
+		header := tt.header
+		if header == "" {
+			header = testHeader
+		}
+		if got := ApplyHeader(tt.src, header, tt.comment); got != tt.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCommentPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		prefix string
+		ok     bool
+	}{
+		{"a/b.go", "//", true},
+		{"x.PY", "#", true},
+		{"schema.sql", "--", true},
+		{"dir/Makefile", "#", true},
+		{"notes.txt", "", false},
+	} {
+		prefix, ok := CommentPrefix(tt.name)
+		if prefix != tt.prefix || ok != tt.ok {
+			t.Errorf("%s: got %q, %v, want %q, %v", tt.name, prefix, ok, tt.prefix, tt.ok)
+		}
+	}
+}
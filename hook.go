@@ -0,0 +1,59 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+// A NestEvent describes a change in the nesting of a chain of writers.  It is
+// passed to the function registered with WithNestHook.  The prefixes are the
+// complete prefixes of the writers, including those of the writers they are
+// nested in.  A writer that is not nested in another writer has a depth of 1.
+type NestEvent struct {
+	Released  bool // the nested writer was closed rather than created
+	OldPrefix string
+	NewPrefix string
+	OldDepth  int
+	NewDepth  int
+}
+
+// WithNestHook causes fn to be called each time a writer is nested in the
+// chain of writers, by calling New with a writer of the chain, and each time
+// the Close method of a nested writer is called, releasing it back to the
+// writer it was nested in.  A framework can use it to write markers, change
+// colors, or enforce policies as the nesting changes.  The hook is not called
+// for the writer created by the New that is passed WithNestHook.
+func WithNestHook(fn func(NestEvent)) Option {
+	return func(in *indenter) {
+		in.s.opts().nestHook = fn
+	}
+}
+
+// nested calls the nest hook, if any, when in is created or, if released is
+// set, closed.
+func (in *indenter) nested(released bool) {
+	if in.s.opt == nil || in.s.opt.nestHook == nil || in.p == nil {
+		return
+	}
+	ev := NestEvent{
+		Released:  released,
+		OldPrefix: string(in.p.prefix),
+		NewPrefix: string(in.prefix),
+		OldDepth:  in.p.depth(),
+		NewDepth:  in.depth(),
+	}
+	if released {
+		ev.OldPrefix, ev.NewPrefix = ev.NewPrefix, ev.OldPrefix
+		ev.OldDepth, ev.NewDepth = ev.NewDepth, ev.OldDepth
+	}
+	in.s.opt.nestHook(ev)
+}
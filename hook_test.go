@@ -0,0 +1,45 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestWithNestHook(t *testing.T) {
+	var events []NestEvent
+	var buf bytes.Buffer
+	w1 := New(&buf, "1>", WithNestHook(func(ev NestEvent) {
+		events = append(events, ev)
+	}))
+	w2 := New(w1, "2>")
+	w3 := New(w2, "3>")
+	w3.(io.Closer).Close()
+	w2.(io.Closer).Close()
+	w1.(io.Closer).Close()
+
+	want := []NestEvent{
+		{OldPrefix: "1>", NewPrefix: "1>2>", OldDepth: 1, NewDepth: 2},
+		{OldPrefix: "1>2>", NewPrefix: "1>2>3>", OldDepth: 2, NewDepth: 3},
+		{Released: true, OldPrefix: "1>2>3>", NewPrefix: "1>2>", OldDepth: 3, NewDepth: 2},
+		{Released: true, OldPrefix: "1>2>", NewPrefix: "1>", OldDepth: 2, NewDepth: 1},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got events:\n%+v\nwant:\n%+v", events, want)
+	}
+}
@@ -0,0 +1,206 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "strings"
+
+// Elements that never have content.
+var htmlVoid = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// Elements whose content is whitespace significant or is not HTML.
+var htmlRaw = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// The states of an htmlScanner.
+const (
+	htmlText = iota
+	htmlTag
+	htmlComment
+	htmlRawText
+)
+
+// An htmlScanner tracks the element nesting depth of an HTML document.
+type htmlScanner struct {
+	state    int
+	depth    int
+	tagDepth int    // depth when the current tag was started
+	name     []byte // name of the current tag
+	inName   bool   // still reading the name
+	end      bool   // the current tag is an end tag
+	decl     bool   // the current tag is <!...> or <?...>
+	slash    bool   // the last non-space byte in the tag was '/'
+	quote    byte   // the quote we are in within a tag
+	raw      string // the raw text element we are in
+}
+
+// scan scans line, updating s.  It returns the depth the line should be
+// indented to, which accounts for end tags at the start of the line.
+func (s *htmlScanner) scan(line string) int {
+	ind := s.depth
+	leadingOnly := true // only end tags so far on this line
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch s.state {
+		case htmlText:
+			if c != '<' {
+				if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+					leadingOnly = false
+				}
+				continue
+			}
+			rest := line[i+1:]
+			switch {
+			case strings.HasPrefix(rest, "!--"):
+				s.state = htmlComment
+				leadingOnly = false
+				i += 3
+				continue
+			case strings.HasPrefix(rest, "/"):
+				s.end = true
+				i++
+			case len(rest) > 0 && (rest[0] == '!' || rest[0] == '?'):
+				s.decl = true
+			case len(rest) > 0 && isHTMLLetter(rest[0]):
+			default:
+				leadingOnly = false
+				continue
+			}
+			if !s.end {
+				leadingOnly = false
+			}
+			s.state = htmlTag
+			s.tagDepth = s.depth
+			s.name = s.name[:0]
+			s.inName = true
+			s.slash = false
+		case htmlTag:
+			switch {
+			case s.quote != 0:
+				if c == s.quote {
+					s.quote = 0
+				}
+			case c == '>':
+				s.endTag()
+				if s.end && leadingOnly {
+					ind = s.depth
+				}
+				s.end = false
+				s.decl = false
+			case c == '"' || c == '\'':
+				s.quote = c
+				s.inName = false
+			case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+				s.inName = false
+			default:
+				if s.inName {
+					if c >= 'A' && c <= 'Z' {
+						c += 'a' - 'A'
+					}
+					s.name = append(s.name, c)
+				}
+				s.slash = c == '/'
+			}
+		case htmlComment:
+			if strings.HasPrefix(line[i:], "-->") {
+				s.state = htmlText
+				i += 2
+			}
+		case htmlRawText:
+			if c == '<' && len(line) > i+len(s.raw)+1 && line[i+1] == '/' && strings.EqualFold(line[i+2:i+2+len(s.raw)], s.raw) {
+				s.raw = ""
+				s.state = htmlTag
+				s.tagDepth = s.depth
+				s.end = true
+				s.name = s.name[:0]
+				s.inName = true
+				s.slash = false
+				leadingOnly = false
+				i++
+			}
+		}
+	}
+	return ind
+}
+
+// endTag is called when the '>' of a tag is found.
+func (s *htmlScanner) endTag() {
+	s.state = htmlText
+	name := string(s.name)
+	switch {
+	case s.decl:
+	case s.end:
+		if s.depth > 0 {
+			s.depth--
+		}
+	case s.slash || htmlVoid[name]:
+	default:
+		s.depth++
+		if htmlRaw[name] {
+			s.raw = name
+			s.state = htmlRawText
+		}
+	}
+}
+
+func isHTMLLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// ReindentHTML returns input with each line indented by one copy of unit per
+// level of element nesting.  Existing leading whitespace is replaced.  Lines
+// that start within a pre, textarea, script, or style element, or within a
+// comment, are left byte for byte intact, as is all of the text within those
+// elements.  Lines that start within a tag, such as the continued attributes
+// of a long start tag, are indented one level deeper than the tag.  Blank lines
+// are reduced to just their newline.
+//
+// ReindentHTML does not know about optional end tags so all non-void elements
+// should be explicitly closed.
+func ReindentHTML(unit, input string) string {
+	var s htmlScanner
+	var b strings.Builder
+	b.Grow(len(input))
+	for _, line := range strings.SplitAfter(input, "\n") {
+		switch s.state {
+		case htmlRawText, htmlComment:
+			s.scan(line)
+			b.WriteString(line)
+			continue
+		case htmlTag:
+			depth := s.tagDepth + 1
+			line = strings.TrimLeft(line, " \t")
+			s.scan(line)
+			b.WriteString(strings.Repeat(unit, depth))
+			b.WriteString(line)
+			continue
+		}
+		line = strings.TrimLeft(line, " \t")
+		if isBlank(line) {
+			if strings.HasSuffix(line, "\n") {
+				b.WriteByte('\n')
+			}
+			continue
+		}
+		depth := s.scan(line)
+		b.WriteString(strings.Repeat(unit, depth))
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "testing"
+
+func TestReindentHTML(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{name: "empty"},
+		{
+			name: "simple",
+			in: `
+<!DOCTYPE html>
+<html>
+<body>
+      <div class="a">
+<p>Hello <b>world</b></p>
+   <br>
+<img src="x" />
+    </div>
+</body>
+</html>
+`[1:],
+			out: `
+<!DOCTYPE html>
+<html>
+  <body>
+    <div class="a">
+      <p>Hello <b>world</b></p>
+      <br>
+      <img src="x" />
+    </div>
+  </body>
+</html>
+`[1:],
+		}, {
+			name: "pre",
+			in: `
+<div>
+<pre>
+  keep   this
+	exactly
+</pre>
+<p>after</p>
+</div>
+`[1:],
+			out: `
+<div>
+  <pre>
+  keep   this
+	exactly
+</pre>
+  <p>after</p>
+</div>
+`[1:],
+		}, {
+			name: "script",
+			in: `
+<div>
+<script>
+  if (a < b && "</div>") {
+      x();
+  }
+</SCRIPT>
+<textarea>
+ <b>not a tag</b>
+</textarea></div>
+`[1:],
+			out: `
+<div>
+  <script>
+  if (a < b && "</div>") {
+      x();
+  }
+</SCRIPT>
+  <textarea>
+ <b>not a tag</b>
+</textarea></div>
+`[1:],
+		}, {
+			name: "multi-line tag",
+			in: `
+<div>
+<input type="text"
+name="a>b"
+value="x">
+<!-- a comment
+   <div>
+-->
+</div></div>
+`[1:],
+			out: `
+<div>
+  <input type="text"
+    name="a>b"
+    value="x">
+  <!-- a comment
+   <div>
+-->
+</div></div>
+`[1:],
+		}, {
+			name: "closing first",
+			in:   "<ul>\n<li>a\n</li><li>b\n</li>\n</ul>\n\n  \n",
+			out:  "<ul>\n  <li>a\n  </li><li>b\n  </li>\n</ul>\n\n\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if out := ReindentHTML("  ", tt.in); out != tt.out {
+				t.Errorf("got:\n%s\nwant:\n%s", out, tt.out)
+			}
+		})
+	}
+}
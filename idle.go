@@ -0,0 +1,76 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "time"
+
+// WithIdleFlush causes a chain of writers using WithBuffering to flush its
+// buffer when nothing has been written to it for d.  This keeps a partial
+// line, such as the prompt "Continue? [y/N] " from a child process, from being
+// held in the buffer while waiting for the rest of the line.  With
+// WithIdleFlush the chain's buffer is protected by a mutex, as the flush is
+// done by another goroutine.  Close stops the idle timer.  WithIdleFlush has
+// no effect on unbuffered writers.
+func WithIdleFlush(d time.Duration) Option {
+	return func(in *indenter) {
+		in.s.opts().idle = d
+	}
+}
+
+// lock locks the chain's buffer if it can be flushed by the idle timer.
+func (s *state) lock() {
+	if s.opt != nil && s.opt.idle > 0 {
+		s.opt.mu.Lock()
+	}
+}
+
+// unlock undoes lock.
+func (s *state) unlock() {
+	if s.opt != nil && s.opt.idle > 0 {
+		s.opt.mu.Unlock()
+	}
+}
+
+// startIdle starts, or restarts, the idle timer if there is buffered output.
+// The buffer must be locked.
+func (s *state) startIdle() {
+	if s.opt == nil || s.opt.idle <= 0 || s.opt.bw == nil || s.opt.bw.Buffered() == 0 {
+		return
+	}
+	if s.opt.timer == nil {
+		s.opt.timer = time.AfterFunc(s.opt.idle, s.idleFlush)
+		return
+	}
+	s.opt.timer.Reset(s.opt.idle)
+}
+
+// idleFlush is called by the idle timer to flush the buffer.  Any error is
+// returned by the next write or flush.
+func (s *state) idleFlush() {
+	s.opt.mu.Lock()
+	defer s.opt.mu.Unlock()
+	if s.opt.bw.Buffered() > 0 {
+		s.opt.bw.Flush()
+	}
+}
+
+// stopIdle stops the idle timer.
+func (s *state) stopIdle() {
+	s.lock()
+	defer s.unlock()
+	if s.opt != nil && s.opt.timer != nil {
+		s.opt.timer.Stop()
+	}
+}
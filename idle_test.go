@@ -0,0 +1,84 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A notifyWriter sends each write on a channel.
+type notifyWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	wrote chan struct{}
+}
+
+func (w *notifyWriter) Write(buf []byte) (int, error) {
+	w.mu.Lock()
+	w.buf.Write(buf)
+	w.mu.Unlock()
+	w.wrote <- struct{}{}
+	return len(buf), nil
+}
+
+func (w *notifyWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestWithIdleFlush(t *testing.T) {
+	nw := &notifyWriter{wrote: make(chan struct{}, 10)}
+	w := New(nw, "> ", WithBuffering(64), WithIdleFlush(time.Millisecond))
+	io.WriteString(w, "a\nContinue? ")
+	select {
+	case <-nw.wrote:
+	case <-time.After(5 * time.Second):
+		t.Fatal("buffer was not flushed")
+	}
+	if got, want := nw.String(), "> a\n> Continue? "; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// The timer is restarted by each write.
+	io.WriteString(w, "y\n")
+	select {
+	case <-nw.wrote:
+	case <-time.After(5 * time.Second):
+		t.Fatal("buffer was not flushed")
+	}
+	if got, want := nw.String(), "> a\n> Continue? y\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := w.(io.Closer).Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestWithIdleFlushUnbuffered(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "> ", WithIdleFlush(time.Millisecond))
+	io.WriteString(w, "a")
+	if got, want := buf.String(), "> a"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if in := w.(*indenter); in.s.opt.timer != nil {
+		t.Errorf("unbuffered writer started a timer")
+	}
+}
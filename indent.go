@@ -51,9 +51,14 @@
 package indent
 
 import (
+	"bufio"
 	"bytes"
 	"io"
 	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -84,60 +89,430 @@ func s2b(s string) []byte {
 // modified after this.
 func b2s(b []byte) string { return *(*string)(unsafe.Pointer(&b)) }
 
-// String returns input with each line in input prefixed by prefix.
-func String(prefix, input string) string {
+// String returns input with each line in input prefixed by prefix.  Options,
+// such as WithParallel, may be used to control how the indenting is done.
+// Any option accepted by New may be used: the result is what a writer
+// returned by New, with the same arguments, writes before it is closed.  If
+// an option stops the writer, as WithMaxOutput does, the result ends where
+// the writer stopped.
+func String(prefix, input string, opts ...Option) string {
+	return StringAt(prefix, input, true, opts...)
+}
+
+// Bytes returns input with each line in input prefixed by prefix.  Options,
+// such as WithParallel, may be used to control how the indenting is done.
+// Any option accepted by New may be used: the result is what a writer
+// returned by New, with the same arguments, writes before it is closed.  If
+// an option stops the writer, as WithMaxOutput does, the result ends where
+// the writer stopped.
+func Bytes(prefix, input []byte, opts ...Option) []byte {
+	return BytesAt(prefix, input, true, opts...)
+}
+
+// StringAt is like String but sol reports whether input starts at the start of
+// a line.  If sol is false the first line of input is not prefixed, as it
+// continues a line that has already been started.
+func StringAt(prefix, input string, sol bool, opts ...Option) string {
+	if len(opts) > 0 {
+		// An error only reports why the result was cut short.
+		out, _ := oneShot([]byte(prefix), s2b(input), sol, opts)
+		return b2s(out)
+	}
 	if len(input) == 0 || len(prefix) == 0 {
+		count(&allocsAvoided, 1)
 		return input
 	}
-	return b2s(indent(s2b(input), s2b(prefix), nil, true))
+	return b2s(indent(s2b(input), s2b(prefix), nil, sol))
 }
 
-// Bytes returns input with each line in input prefixed by prefix.
-func Bytes(prefix, input []byte) []byte {
+// BytesAt is like Bytes but sol reports whether input starts at the start of a
+// line.  If sol is false the first line of input is not prefixed, as it
+// continues a line that has already been started.
+func BytesAt(prefix, input []byte, sol bool, opts ...Option) []byte {
+	if len(opts) > 0 {
+		// An error only reports why the result was cut short.
+		out, _ := oneShot(append([]byte(nil), prefix...), input, sol, opts)
+		return out
+	}
 	if len(input) == 0 || len(prefix) == 0 {
+		count(&allocsAvoided, 1)
 		return input
 	}
-	return indent(input, prefix, nil, true)
+	return indent(input, prefix, nil, sol)
+}
+
+// Lines returns a new slice containing lines with each line prefixed by
+// prefix.  An element of lines that contains newlines is split into multiple
+// lines, each of which is prefixed.  A single trailing newline only ends the
+// line and does not start a new one.  The elements of the result do not
+// contain newlines.
+func Lines(prefix string, lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\n")
+		for {
+			x := strings.IndexByte(line, '\n')
+			if x < 0 {
+				break
+			}
+			out = append(out, prefix+line[:x])
+			line = line[x+1:]
+		}
+		out = append(out, prefix+line)
+	}
+	return out
+}
+
+// Append appends src, with each line prefixed by prefix, to dst and returns
+// the extended buffer, as the strconv Append functions do.  Reusing dst across
+// calls avoids the allocation made by Bytes.  When dst must grow it grows as
+// append does, so building a large buffer with many calls to Append is
+// efficient.
+func Append(dst, prefix, src []byte) []byte {
+	if len(prefix) == 0 {
+		return append(dst, src...)
+	}
+	if need := indentSize(src, prefix, nil, true); cap(dst)-len(dst) < need {
+		n := len(dst)
+		dst = append(dst[:cap(dst)], make([]byte, n+need-cap(dst))...)[:n]
+	}
+	return appendIndent(dst, src, prefix, nil, true)
+}
+
+// WriteIndentedTo writes src to dst with each line prefixed by prefix.  Unlike
+// String and Bytes, which build the indented output in a buffer, it passes the
+// prefix and each line of src directly to dst, so no memory proportional to
+// the size of src is allocated.
+// This comes at the cost of two writes per line, so dst should normally be
+// buffered.  WriteIndentedTo returns the number of bytes written to dst.
+func WriteIndentedTo(dst io.Writer, prefix string, src []byte) (int64, error) {
+	p := s2b(prefix)
+	var total int64
+	write := func(buf []byte) error {
+		if len(buf) == 0 {
+			return nil
+		}
+		n, err := dst.Write(buf)
+		count(&writes, 1)
+		total += int64(n)
+		if err == nil && n < len(buf) {
+			err = io.ErrShortWrite
+		}
+		return err
+	}
+	for len(src) > 0 {
+		line := src
+		if x := bytes.IndexByte(src, '\n'); x >= 0 {
+			line = src[:x+1]
+		}
+		if err := write(p); err != nil {
+			return total, err
+		}
+		if err := write(line); err != nil {
+			return total, err
+		}
+		src = src[len(line):]
+	}
+	return total, nil
+}
+
+// oneShot returns input written to a writer, configured with opts, that
+// prefixes lines with prefix and is then closed, along with the first error
+// of the writer.  It is used by String and Bytes when they are given options.
+// The writer owns prefix.
+func oneShot(prefix, input []byte, sol bool, opts []Option) ([]byte, error) {
+	var buf bytes.Buffer
+	in := &indenter{
+		prefix: prefix,
+		s:      &state{w: &buf, sol: sol},
+	}
+	for _, opt := range opts {
+		opt(in)
+	}
+	if in.direct() {
+		return in.appendIndent(nil, input, sol), nil
+	}
+	buf.Grow(indentSize(input, in.linePrefix(), in.postfix(), sol))
+	_, err := in.Write(input)
+	if cerr := in.Close(); err == nil {
+		err = cerr
+	}
+	return buf.Bytes(), err
+}
+
+// direct reports whether the output of in is its input indented by
+// appendIndent, so that oneShot can indent the input straight into its
+// result rather than writing it.
+func (in *indenter) direct() bool {
+	if in.s.slow || in.s.trim || in.s.disabled || in.hanging() || in.err() != nil {
+		return false
+	}
+	if in.opt != nil && in.opt.finalNewline {
+		return false
+	}
+	o := in.s.opt
+	return o == nil || o.max == 0 && o.trace == nil
 }
 
 // An indenter is an io.Writer.  All indenters in an uninterruped chain share
-// the same sol value.
+// the same state.  The fields used by the rarely used options are kept in opt,
+// which is only allocated when one of those options is given.
 type indenter struct {
-	w       io.Writer
-	prefix  []byte
-	postfix []byte
-	sol     *bool     // true if we are at the start of a line
-	p       *indenter // the indenter we wrapped
+	prefix []byte
+	s      *state         // shared by the chain
+	p      *indenter      // the indenter we wrapped
+	opt    *writerOptions // allocated by opts
+}
+
+// A writerOptions holds the part of an indenter used by the rarely used
+// options.
+type writerOptions struct {
+	postfix []byte // set by NewPostfix and inherited when nesting
+	tagged  []byte // prefix with the depth tag, set by linePrefix
+	first   []byte // prefix of the first line, set by NewHanging
+
+	prefixFunc   func(line int) string // set by WithPrefixFunc
+	finalNewline bool                  // set by WithFinalNewline
+	err          error                 // set when the limits of the chain are exceeded
+}
+
+// A state is the part of an indenter shared by all the indenters in a chain.
+// The fields used by the rarely used options are kept in opt, which is only
+// allocated when one of those options is given, so a plain writer stays small.
+// The counts are kept in the state itself as every writer maintains them.
+type state struct {
+	w           io.Writer // the underlying writer
+	sol         bool      // true if we are at the start of a line
+	reuse       bool      // keep opt.buf between writes
+	disabled    bool      // set by Disable
+	closeOutput bool      // set by WithCloseOutput
+	slow        bool      // use transformWrite, set by the options it implements
+	trim        bool      // set by WithTrimTrailingSpace
+	tags        bool      // set by WithDepthTags
+	check       bool      // set by WithRaceCheck
+
+	lines int64 // number of newlines written on the current page
+	stats Stats // returned by Stats
+
+	opt *options // allocated by opts
+}
+
+// An options holds the part of a state used by the rarely used options.
+type options struct {
+	buf      []byte        // the output buffer when reuse is set or after Grow
+	parallel int           // minimum size of buffers to indent in parallel
+	bw       *bufio.Writer // set by WithBuffering
+	max      int64         // maximum number of bytes to write, if not 0
+	busy     int32         // a write is in progress, used by WithRaceCheck
+
+	trace func(format string, v ...interface{}) // set by WithTrace
+
+	idle  time.Duration // set by WithIdleFlush
+	mu    sync.Mutex    // protects bw when idle is set
+	timer *time.Timer   // flushes bw when idle
+
+	gutter  int // minimum width of each level's prefix
+	tabStop int // expand tabs in prefixes if not 0
+
+	// The options below transform the lines being written.  They are
+	// implemented by transformWrite, which is used when slow is set.
+	out    outbuf // the output of transformWrite
+	dropCR bool   // set by WithDropCR
+	eager  bool   // set by WithEagerPrefix
+	early  []byte // the prefix written by WithEagerPrefix that ends the output
+
+	stripANSI bool // set by WithStripANSI
+	esc       int  // state of the escape sequence parser
+	visible   bool // set by WithVisibleWhitespace
+	inText    bool // the leading whitespace of the line has been written
+
+	lineNo int // number of lines started, used by WithPrefixFunc
+
+	afterIndent bool // set by WithPrefixAfterIndent
+	pending     bool // the prefix is waiting for the leading whitespace
+
+	marker     []byte // set by WithCommentColumn
+	commentCol int    // set by WithCommentColumn
+	dcol       int    // display column of the line after its prefix
+	held       []byte // bytes held while looking for the marker
+	aligned    bool   // the marker of the line has been aligned
+
+	maxLines int      // set by WithMaxLines
+	maxBytes int64    // set by WithMaxBytes
+	keep     int      // set by WithKeepLast
+	eliding  bool     // the limit was reached
+	elided   int64    // number of lines elided
+	tail     [][]byte // the last keep lines elided
+
+	formFeed bool   // set by WithFormFeed
+	divider  []byte // set by WithFormFeed
+
+	maxLine  int    // set by WithMaxLineLen
+	onExceed Action // set by WithMaxLineLen
+	col      int    // bytes on the current line after the prefix
+
+	nestHook func(NestEvent) // set by WithNestHook
+
+	maxDepth  int    // set by WithMaxDepth
+	onDeep    Action // set by WithMaxDepth
+	maxPrefix int    // set by WithMaxPrefixLen
+	onLong    Action // set by WithMaxPrefixLen
+
+	ws      []byte // trailing whitespace held by trimWrite
+	trimmed []byte // the output of trimWrite
+}
+
+// opts returns s.opt, allocating it if needed.  It is used by the options
+// that set the fields of s.opt.
+func (s *state) opts() *options {
+	if s.opt == nil {
+		s.opt = &options{}
+	}
+	return s.opt
+}
+
+// opts returns in.opt, allocating it if needed.
+func (in *indenter) opts() *writerOptions {
+	if in.opt == nil {
+		in.opt = &writerOptions{}
+	}
+	return in.opt
+}
+
+// postfix returns the postfix of in's lines, if any.
+func (in *indenter) postfix() []byte {
+	if in.opt == nil {
+		return nil
+	}
+	return in.opt.postfix
+}
+
+// err returns the error of in, if any.
+func (in *indenter) err() error {
+	if in.opt == nil {
+		return nil
+	}
+	return in.opt.err
+}
+
+// setPrefix sets in's prefix to prefix, dropping the tagged copy of the
+// previous one.
+func (in *indenter) setPrefix(prefix []byte) {
+	in.prefix = prefix
+	if in.opt != nil {
+		in.opt.tagged = nil
+	}
+}
+
+// hanging reports whether in was created by NewHanging and has not yet
+// written its first line.
+func (in *indenter) hanging() bool {
+	return in.opt != nil && in.opt.first != nil
+}
+
+// padPrefix pads the part of in's prefix that in added to the chain with
+// spaces to the width set by WithGutter.
+func (in *indenter) padPrefix() {
+	if in.s.opt == nil {
+		return
+	}
+	start := 0
+	if in.p != nil {
+		start = len(in.p.prefix)
+	}
+	if pad := in.s.opt.gutter - displayWidth(string(in.prefix[start:])); pad > 0 {
+		in.prefix = append(in.prefix[:len(in.prefix):len(in.prefix)], bytes.Repeat([]byte{' '}, pad)...)
+	}
+}
+
+// expandPrefix expands the tabs in in's prefix to the tab stops set by
+// WithTabStop.
+func (in *indenter) expandPrefix() {
+	if in.s.opt == nil || in.s.opt.tabStop == 0 {
+		return
+	}
+	n := in.s.opt.tabStop
+	if bytes.IndexByte(in.prefix, '\t') < 0 {
+		return
+	}
+	var prefix []byte
+	col := 0
+	for _, r := range string(in.prefix) {
+		if r == '\t' {
+			for pad := n - col%n; pad > 0; pad-- {
+				prefix = append(prefix, ' ')
+			}
+			col += n - col%n
+			continue
+		}
+		prefix = append(prefix, string(r)...)
+		col += runeWidth(r, col)
+	}
+	in.prefix = prefix
 }
 
 // NewWriter is the name used in github.com/openconfig/goyang/pkg/indent.
-var NewWriter = New
+var NewWriter = func(w io.Writer, prefix string) io.Writer { return New(w, prefix) }
 
 // New returns a writer that will prefix all lines written to it with prefix and
 // then writes the results to w.  New is intelligent about recursive calls to
-// New.  New return w if prefix is the empty string.  When nesting, New does not
-// assume it is at the start of a line, it maintains this information as you
-// nest and unwind indenters.  It normally is best to only transition between
-// nested writers after a newline has been written.
-func New(w io.Writer, prefix string) io.Writer {
-	if len(prefix) == 0 {
+// New.  New return w if prefix is the empty string and there are no options.
+// When nesting, New does not assume it is at the start of a line, it maintains
+// this information as you nest and unwind indenters.  It normally is best to
+// only transition between nested writers after a newline has been written.
+//
+// Options apply to the writer being created.  Options that describe the shared
+// state of a chain of nested writers, such as WithReusedBuffer, apply to the
+// entire chain.
+func New(w io.Writer, prefix string, opts ...Option) io.Writer {
+	if len(prefix) == 0 && len(opts) == 0 {
 		return w
 	}
+	in := newIndenter(w, prefix)
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+// NewAt is like New but sol reports whether w is at the start of a line.  If
+// sol is false, the caller has already written part of a line to w, so the
+// text written up to the first newline continues that line and is not
+// prefixed.  If w is a writer returned by New then sol replaces the chain's
+// record of whether it is at the start of a line.  Unlike New, NewAt always
+// returns a new writer.
+func NewAt(w io.Writer, prefix string, sol bool, opts ...Option) io.Writer {
+	in := newIndenter(w, prefix)
+	in.s.sol = sol
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+// newIndenter returns an indenter that adds prefix to lines and writes them
+// to w, nesting within w if w is an indenter.
+func newIndenter(w io.Writer, prefix string) *indenter {
 	// If we are indenting an indenter then we can just combine the
 	// indents.
-	if in, ok := w.(*indenter); ok {
-		return &indenter{
-			w:      in.w,
-			prefix: append(in.prefix, prefix...),
-			sol:    in.sol,
-			p:      in,
+	if p, ok := w.(*indenter); ok {
+		in := &indenter{
+			prefix: append(p.prefix[:len(p.prefix):len(p.prefix)], prefix...),
+			s:      p.s,
+			p:      p,
+		}
+		if postfix := p.postfix(); postfix != nil {
+			in.opts().postfix = postfix
 		}
+		in.padPrefix()
+		in.expandPrefix()
+		in.limitPrefix()
+		in.nested(false)
+		return in
 	}
-	sol := true
 	return &indenter{
-		w:      w,
 		prefix: []byte(prefix),
-		sol:    &sol,
+		s:      &state{w: w, sol: true},
 	}
 }
 
@@ -145,12 +520,10 @@ func NewPostfix(w io.Writer, indent, postfix string) io.Writer {
 	if indent == "" && postfix == "" {
 		return w
 	}
-	sol := true
 	return &indenter{
-		w:       w,
-		prefix:  []byte(indent),
-		postfix: []byte(postfix),
-		sol:    &sol,
+		prefix: []byte(indent),
+		s:      &state{w: w, sol: true},
+		opt:    &writerOptions{postfix: []byte(postfix)},
 	}
 }
 
@@ -168,114 +541,659 @@ func NewPostfix(w io.Writer, indent, postfix string) io.Writer {
 //	1> abc123
 //	1> 2> 456def
 func (in *indenter) Write(buf []byte) (int, error) {
+	if err := in.err(); err != nil {
+		return 0, err
+	}
+	if in.s.check || in.s.opt != nil && in.s.opt.trace != nil {
+		return in.debugWrite("Write", len(buf), func() (int, error) {
+			if in.hanging() {
+				return in.hangingWrite(buf)
+			}
+			return in.indentWrite(buf)
+		})
+	}
+	if in.hanging() {
+		return in.hangingWrite(buf)
+	}
+	return in.indentWrite(buf)
+}
+
+// indentWrite is Write without tracing.
+func (in *indenter) indentWrite(buf []byte) (int, error) {
 	if len(buf) == 0 {
 		return 0, nil
 	}
-	sol := *in.sol
-	nbuf := indent(buf, in.prefix, in.postfix, sol)
-	r, err := in.w.Write(nbuf)
+	if in.s.disabled {
+		return in.rawWrite(buf)
+	}
+	if in.s.slow {
+		return in.transformWrite(buf)
+	}
+	sol := in.s.sol
+	nbuf := in.appendIndent(in.buffer(), buf, sol)
+	if in.s.reuse {
+		in.s.opt.buf = nbuf
+	}
+	r, err := in.write(nbuf)
 	if r == len(nbuf) {
-		*in.sol = nbuf[r-1] == '\n'
+		in.s.sol = nbuf[r-1] == '\n'
+		in.s.stats.Prefixes += prefixCount(buf, in.linePrefix(), sol)
 		return len(buf), err
 	}
+	return in.short(buf, nbuf, r, sol), err
+}
 
-	// The write failed someplace.  Figure out how much of what we wrote
-	// came from buf and return that amount.
-
-	nbuf = nbuf[:r]
+// prefixCount returns the number of prefixes indenting buf adds.
+func prefixCount(buf, prefix []byte, sol bool) int64 {
+	if len(prefix) == 0 || len(buf) == 0 {
+		return 0
+	}
+	n := int64(bytes.Count(buf, []byte{'\n'}))
+	if buf[len(buf)-1] == '\n' {
+		n--
+	}
+	if sol {
+		n++
+	}
+	return n
+}
 
-	if r == 0 {
+// WriteAll writes the concatenation of bufs to the writer using a single
+// output buffer and a single Write to the underlying writer.  It returns the
+// number of bytes from bufs that were written.  It is useful for producers
+// that generate vectors of segments, such as net.Buffers:
+//
+//	w.(interface {
+//		WriteAll(...[]byte) (int, error)
+//	}).WriteAll(nb...)
+func (in *indenter) WriteAll(bufs ...[]byte) (int, error) {
+	if err := in.err(); err != nil {
 		return 0, err
 	}
+	if in.s.check || in.s.opt != nil && in.s.opt.trace != nil {
+		total := 0
+		for _, buf := range bufs {
+			total += len(buf)
+		}
+		return in.debugWrite("WriteAll", total, func() (int, error) {
+			return in.writeAll(bufs)
+		})
+	}
+	return in.writeAll(bufs)
+}
 
-	// If sol was true then we started with a prefix, if not, we did not.
-	// So strip the initial prefix if we wrote one.
-	if sol {
-		r -= len(in.prefix)
-		if r <= 0 {
-			return 0, err
+// writeAll is WriteAll without tracing.
+func (in *indenter) writeAll(bufs [][]byte) (int, error) {
+	if in.s.disabled {
+		return in.rawWrite(bytes.Join(bufs, nil))
+	}
+	if in.hanging() {
+		return in.hangingWrite(bytes.Join(bufs, nil))
+	}
+	if in.s.slow {
+		return in.indentWrite(bytes.Join(bufs, nil))
+	}
+	prefix := in.linePrefix()
+	sol := in.s.sol
+	need := 0
+	total := 0
+	for _, buf := range bufs {
+		if len(buf) == 0 {
+			continue
+		}
+		need += indentSize(buf, prefix, in.postfix(), sol)
+		total += len(buf)
+		sol = buf[len(buf)-1] == '\n'
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	nbuf := grow(in.buffer(), need)[:need]
+	sol = in.s.sol
+	off := 0
+	for _, buf := range bufs {
+		if len(buf) == 0 {
+			continue
+		}
+		n := indentSize(buf, prefix, in.postfix(), sol)
+		fillIndent(nbuf[off:off+n], buf, prefix, in.postfix(), sol)
+		off += n
+		sol = buf[len(buf)-1] == '\n'
+	}
+	if in.s.reuse {
+		in.s.opt.buf = nbuf
+	}
+	r, err := in.write(nbuf)
+	if r == len(nbuf) {
+		sol = in.s.sol
+		for _, buf := range bufs {
+			in.s.stats.Prefixes += prefixCount(buf, prefix, sol)
+			if len(buf) > 0 {
+				sol = buf[len(buf)-1] == '\n'
+			}
 		}
-		nbuf = nbuf[len(in.prefix):]
+		in.s.sol = sol
+		return total, err
 	}
+	// This is the uncommon case so we do not mind the allocation.
+	return in.short(bytes.Join(bufs, nil), nbuf, r, in.s.sol), err
+}
 
-	nl := bytes.Count(nbuf, []byte{'\n'})
-	if nl == 0 {
-		// There are no newlines so there are no prefixes left to
-		// account for.
-		*in.sol = buf[r-1] == '\n'
-		return r, err
+// ReadFrom writes the text read from r to the writer until r returns io.EOF
+// or an error.  It returns the number of bytes read from r that were written.
+// ReadFrom is used by io.Copy.  The text is read in fixed size chunks and a
+// single output buffer is used for all of them, so the memory used does not
+// depend on the size of the input.  An error other than io.EOF returned by r
+// is returned by ReadFrom.
+func (in *indenter) ReadFrom(r io.Reader) (int64, error) {
+	if !in.s.reuse {
+		in.s.reuse = true
+		in.s.opts()
+		defer func() {
+			in.s.reuse = false
+			in.s.opt.buf = nil
+		}()
+	}
+	buf := make([]byte, readChunk)
+	var total int64
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			w, werr := in.Write(buf[:n])
+			total += int64(w)
+			if werr != nil {
+				return total, werr
+			}
+		}
+		switch err {
+		case nil:
+		case io.EOF:
+			return total, nil
+		default:
+			return total, err
+		}
 	}
+}
 
-	// Find how much we wrote up to and including the last newline
-	ln := bytes.LastIndex(nbuf, []byte{'\n'})
-	r = ln - (nl-1)*len(in.prefix) + 1
+// dst returns the writer indented output is written to.
+func (in *indenter) dst() io.Writer {
+	if in.s.opt != nil && in.s.opt.bw != nil {
+		return in.s.opt.bw
+	}
+	return in.s.w
+}
 
-	// Now figure out how many bytes were after the last newline.  If more
-	// than our prefix then add those back into the total number of bytes
-	// read from buf.
-	x := len(nbuf) - ln - 1
-	if x > len(in.prefix) {
-		r += x - len(in.prefix)
+// write writes nbuf to the underlying writer.  It only writes as much of nbuf
+// as permitted by WithMaxOutput, returning ErrMaxOutput if it could not write
+// all of nbuf.
+func (in *indenter) write(nbuf []byte) (int, error) {
+	if in.s.trim {
+		return in.trimWrite(nbuf)
+	}
+	return in.output(nbuf)
+}
+
+// output is write without the trimming of WithTrimTrailingSpace.
+func (in *indenter) output(nbuf []byte) (int, error) {
+	var err error
+	if in.s.opt != nil && in.s.opt.max > 0 {
+		if left := in.s.opt.max - in.s.stats.Bytes; int64(len(nbuf)) > left {
+			nbuf = nbuf[:left]
+			err = ErrMaxOutput
+		}
+	}
+	in.s.lock()
+	r, werr := in.dst().Write(nbuf)
+	in.s.startIdle()
+	in.s.unlock()
+	count(&writes, 1)
+	nl := int64(bytes.Count(nbuf[:r], []byte{'\n'}))
+	in.s.lines += nl
+	in.s.stats.Lines += nl
+	in.s.stats.Bytes += int64(r)
+	if werr != nil {
+		err = werr
 	}
-	*in.sol = buf[r-1] == '\n'
 	return r, err
 }
 
-// indent returns buf with each line prefixed by prefix.  The sol flag indicates
-// if we are at the start of a line.
-func indent(buf, prefix, postfix []byte, sol bool) []byte {
-	if len(buf) == 0 || (len(prefix) == 0 && len(postfix) == 0) {
-		return buf
+// Flush writes any output buffered by the WithBuffering option, or held by
+// WithCommentColumn, to the underlying writer.  If the underlying writer has a
+// Flush method, such as a bufio.Writer or a tabwriter.Writer, it is then
+// flushed as well.  Flushing any writer of a chain, no matter how deeply it is
+// nested, flushes the underlying writer, so there is no need to Unwrap it.
+func (in *indenter) Flush() error {
+	if o := in.s.opt; o != nil && len(o.held) > 0 {
+		held := o.held
+		o.held = nil
+		if _, err := in.write(held); err != nil {
+			return err
+		}
+	}
+	if in.s.opt != nil && in.s.opt.bw != nil {
+		in.s.lock()
+		err := in.s.opt.bw.Flush()
+		in.s.unlock()
+		if err != nil {
+			return err
+		}
+	}
+	if f, ok := in.s.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Sync flushes the writer, as Flush does, and then calls the Sync method of
+// the underlying writer, if it has one, such as an os.File.  Sync returns nil
+// if the underlying writer has no Sync method.
+func (in *indenter) Sync() error {
+	if err := in.Flush(); err != nil {
+		return err
+	}
+	if s, ok := in.s.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Prefix returns the prefix the writer starts each line with, including the
+// prefixes of the writers it is nested within.  A library that wraps text
+// written to an io.Writer can use its width to compute how much of a line is
+// left:
+//
+//	if p, ok := w.(interface{ Prefix() string }); ok {
+//		width -= len(p.Prefix())
+//	}
+func (in *indenter) Prefix() string {
+	return string(in.prefix)
+}
+
+// Depth returns the nesting depth of the writer.  A writer that is not nested
+// in another writer returned by New has a depth of 1.
+func (in *indenter) Depth() int {
+	return in.depth()
+}
+
+// Disable disables the indentation of the chain of writers until Enable is
+// called.  Text written while disabled is passed to the underlying writer
+// untouched, although it is still subject to WithBuffering and WithMaxOutput.
+// Whether the chain is at the start of a line is kept up to date, so a line
+// started while disabled is not prefixed when the chain is enabled again.
+// This is useful for writing pre-formatted text, such as a hex dump, within
+// indented output.
+func (in *indenter) Disable() {
+	in.s.disabled = true
+}
+
+// Enable enables the indentation of the chain of writers disabled by Disable.
+func (in *indenter) Enable() {
+	in.s.disabled = false
+}
+
+// Raw calls fn with a writer that writes to the underlying writer of the
+// chain without indenting, as if the chain were disabled by Disable.  It is
+// used to write a region of text, such as terminal control sequences or a
+// pre-rendered table, that must not be altered.  After fn returns, the chain
+// knows if the text fn wrote ended part way through a line.  The writer
+// passed to fn must not be used after fn returns.
+//
+//	w.(interface{ Raw(func(io.Writer)) }).Raw(func(w io.Writer) {
+//		w.Write(table)
+//	})
+func (in *indenter) Raw(fn func(io.Writer)) {
+	fn(rawWriter{in})
+}
+
+// A rawWriter is the writer passed to the function given to Raw.
+type rawWriter struct {
+	in *indenter
+}
+
+func (r rawWriter) Write(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
 	}
+	return r.in.rawWrite(buf)
+}
 
+// rawWrite writes buf to the underlying writer without indenting it.
+func (in *indenter) rawWrite(buf []byte) (int, error) {
+	r, err := in.output(buf)
+	if r > 0 {
+		in.s.sol = buf[r-1] == '\n'
+		if in.s.opt != nil {
+			in.s.opt.early = nil
+		}
+	}
+	return r, err
+}
+
+// AtLineStart reports whether the next byte written to the writer starts a
+// new line.  It is true for a new writer and after writing a newline.
+func (in *indenter) AtLineStart() bool {
+	return in.s.sol
+}
+
+// Stats are counts of the output of a chain of writers.
+type Stats struct {
+	Lines    int64 // newlines written
+	Prefixes int64 // prefixes written
+	Bytes    int64 // bytes written to the underlying writer
+}
+
+// Stats returns the counts of the output written by the chain of writers,
+// including that of the writers nested in it, since the chain was created or
+// last Reset.  A report generator can use the count of lines to paginate its
+// output without buffering it.  After a short write by a chain with options
+// that transform lines, such as WithDropCR, Prefixes may include prefixes
+// that were not written.
+func (in *indenter) Stats() Stats {
+	return in.s.stats
+}
 
-	hasPostfix := false
-	if len(postfix) > 0 {
-		hasPostfix = true
-		postfix = append(postfix, '\n')
+// SetOutput sets the underlying writer of the chain of writers to w, as
+// log.Logger's SetOutput does.  The prefixes and whether the writers are at
+// the start of a line are kept, so a long running writer can be redirected,
+// such as when rotating a log file, without recreating its nested writers.
+// Output buffered by WithBuffering is first flushed to the previous writer.
+// Call Flush before SetOutput to learn of any error writing that output.
+func (in *indenter) SetOutput(w io.Writer) {
+	in.s.lock()
+	defer in.s.unlock()
+	in.s.w = w
+	if in.s.opt != nil && in.s.opt.bw != nil {
+		in.s.opt.bw.Flush()
+		in.s.opt.bw.Reset(w)
 	}
+}
 
-	lines := bytes.SplitAfter(buf, []byte{'\n'})
+// Close flushes any buffered output.  Closing a nested writer calls the hook
+// registered by WithNestHook and closing the outermost writer stops the timer
+// started by WithIdleFlush.  A writer created with WithFinalNewline first ends
+// a line that was left unfinished.  Close does not close the underlying writer
+// unless the chain was created with WithCloseOutput.
+func (in *indenter) Close() error {
+	if in.opt != nil && in.opt.finalNewline && !in.s.sol {
+		if _, err := in.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+	}
+	if in.p != nil {
+		in.nested(true)
+	} else {
+		in.s.stopIdle()
+		if in.s.opt != nil {
+			in.s.opt.ws = nil
+			if err := in.writeElided(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := in.Flush(); err != nil || in.p != nil || !in.s.closeOutput {
+		return err
+	}
+	if c, ok := in.s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
 
-	n := len(lines) - 1
-	// If buf ends in a newline there will be a zero slice at the of the the
-	// lines.  It needs to be removed so we don't appen and extra prefix.
-	eol := len(lines[n]) == 0
-	if eol  {
-		// The last byte of buf was a newline.
-		lines = lines[:n]
-		n--
+// buffer returns the empty buffer to append the output of a Write to.  A
+// buffer provided by Grow is only used once unless the buffer is reused.
+func (in *indenter) buffer() []byte {
+	o := in.s.opt
+	if o == nil {
+		return nil
+	}
+	buf := o.buf[:0]
+	if !in.s.reuse {
+		o.buf = nil
 	}
+	return buf
+}
 
-	need := len(buf) + n*len(prefix)
-	if hasPostfix {
-		need += n * (len(postfix) -  1)
-		if eol {
-			need += len(postfix) - 1
+// Grow grows the writer's output buffer, if necessary, so that the output of
+// the next Write, or WriteAll, of up to n bytes of indented output does not
+// allocate.  It is useful before a single large Write when the size of its
+// output is known, such as the size of the input plus a prefix for each line.
+// The buffer is retained after the next Write only if the writer was created
+// with WithReusedBuffer.  Grow panics if n is negative.
+func (in *indenter) Grow(n int) {
+	if n < 0 {
+		panic("indent.Grow: negative count")
+	}
+	if in.s.slow {
+		if o := &in.s.opt.out; cap(o.buf) < n {
+			o.buf = make([]byte, 0, n)
 		}
+		return
 	}
-	if sol {
-		need += len(prefix)
+	if o := in.s.opts(); cap(o.buf) < n {
+		o.buf = make([]byte, 0, n)
+	}
+}
+
+// short is called when only r bytes of nbuf, which is buf indented starting
+// with sol, were written.  It updates in's start of line state and returns
+// how many bytes of buf were written.
+func (in *indenter) short(buf, nbuf []byte, r int, sol bool) int {
+	// The write failed someplace.  Walk through buf, accounting for the
+	// prefixes and postfixes we added, to find how much of what we wrote
+	// came from buf.
+	prefix := in.linePrefix()
+	out := 0 // bytes of nbuf accounted for
+	n := 0   // bytes of buf accounted for
+	for n < len(buf) {
+		if sol {
+			if r-out <= len(prefix) {
+				break
+			}
+			out += len(prefix)
+			if len(prefix) > 0 {
+				in.s.stats.Prefixes++
+			}
+		}
+		line := buf[n:]
+		x := bytes.IndexByte(line, '\n')
+		if x < 0 {
+			x = len(line)
+		}
+		if r-out < x {
+			n += r - out
+			break
+		}
+		n += x
+		out += x
+		if n == len(buf) || r-out < len(in.postfix())+1 {
+			break
+		}
+		// We wrote the postfix and the newline.
+		out += len(in.postfix()) + 1
+		n++
+		sol = true
+	}
+	if n > 0 {
+		in.s.sol = buf[n-1] == '\n'
+	}
+	return n
+}
+
+// Reset returns the chain of writers to the state it was in when created: at
+// the start of a line, with nothing written, and with any text held for the
+// next write or buffered by WithBuffering discarded.  The error of the writer
+// is cleared, though a writer that exceeds a limit set by WithMaxDepth or
+// WithMaxPrefixLen with Fail still fails.  Reset also releases the output
+// buffer retained by a writer created with the WithReusedBuffer option.  The
+// buffer is reallocated as needed by the next Write.
+func (in *indenter) Reset() {
+	in.s.lock()
+	if in.s.opt != nil && in.s.opt.bw != nil {
+		in.s.opt.bw.Reset(in.s.w)
+	}
+	in.s.unlock()
+	in.s.reset()
+	if in.s.opt != nil {
+		in.s.opt.buf = nil
+	}
+	if in.opt != nil {
+		in.opt.err = nil
+	}
+	in.limitPrefix()
+}
+
+// ResetOutput is Reset followed by SetOutput(w), except that the output
+// buffer retained by WithReusedBuffer is kept.  It allows a chain of writers
+// to be reused, such as from a sync.Pool, for a new writer rather than being
+// created again.  Output buffered by WithBuffering is discarded.
+func (in *indenter) ResetOutput(w io.Writer) {
+	in.s.lock()
+	in.s.w = w
+	if in.s.opt != nil && in.s.opt.bw != nil {
+		in.s.opt.bw.Reset(w)
+	}
+	in.s.unlock()
+	in.s.reset()
+}
+
+// reset resets the line state and the counters of s.
+func (s *state) reset() {
+	s.sol = true
+	s.lines = 0
+	s.stats = Stats{}
+	o := s.opt
+	if o == nil {
+		return
+	}
+	o.lineNo = 0
+	o.out = outbuf{}
+	o.early = nil
+	o.esc = escNone
+	o.inText = false
+	o.pending = false
+	o.dcol = 0
+	o.held = nil
+	o.aligned = false
+	o.eliding = false
+	o.elided = 0
+	o.tail = nil
+	o.col = 0
+	o.ws = nil
+}
+
+// appendIndent is like the appendIndent function but uses the prefix, postfix,
+// and options of in.
+func (in *indenter) appendIndent(dst, buf []byte, sol bool) []byte {
+	prefix := in.linePrefix()
+	if o := in.s.opt; o != nil && o.parallel > 0 && len(buf) >= o.parallel {
+		return appendIndentParallel(dst, buf, prefix, in.postfix(), sol, runtime.GOMAXPROCS(0))
+	}
+	return appendIndent(dst, buf, prefix, in.postfix(), sol)
+}
+
+// indent returns buf with each line prefixed by prefix.  The sol flag indicates
+// if we are at the start of a line.
+func indent(buf, prefix, postfix []byte, sol bool) []byte {
+	if len(buf) == 0 || (len(prefix) == 0 && len(postfix) == 0) {
+		return buf
+	}
+	return appendIndent(nil, buf, prefix, postfix, sol)
+}
+
+// appendIndent appends buf, with each line prefixed by prefix, to dst and
+// returns the extended slice.  The sol flag indicates if we are at the start
+// of a line.
+func appendIndent(dst, buf, prefix, postfix []byte, sol bool) []byte {
+	if len(buf) == 0 {
+		return dst
 	}
 
-	buf = make([]byte, need)
+	need := indentSize(buf, prefix, postfix, sol)
+	start := len(dst)
+	dst = grow(dst, need)
+	dst = dst[:start+need]
+	fillIndent(dst[start:], buf, prefix, postfix, sol)
+	return dst
+}
+
+// fillIndent fills out, which must be exactly indentSize(buf, prefix, postfix,
+// sol) bytes long, with the indented buf.
+func fillIndent(out, buf, prefix, postfix []byte, sol bool) {
+	if len(prefix) == 1 && len(postfix) == 0 {
+		fillIndentByte(out, buf, prefix[0], sol)
+		return
+	}
 
 	wrote := 0
-	for i, line := range lines {
+	for len(buf) > 0 {
 		// All line, except perhaps the first, get the prefix.
 		if sol {
-			wrote += copy(buf[wrote:], prefix)
+			wrote += copy(out[wrote:], prefix)
 		}
 		sol = true
-		if hasPostfix && (i < len(lines) - 1 || eol) {
-			// Postfix has the trailing newline
-			wrote += copy(buf[wrote:], line[:len(line)-1])
-			wrote += copy(buf[wrote:], postfix)
-		} else {
-			wrote += copy(buf[wrote:], line)
+		x := bytes.IndexByte(buf, '\n')
+		if x < 0 {
+			copy(out[wrote:], buf)
+			break
 		}
+		wrote += copy(out[wrote:], buf[:x])
+		wrote += copy(out[wrote:], postfix)
+		out[wrote] = '\n'
+		wrote++
+		buf = buf[x+1:]
 	}
-	return buf
+}
+
+// fillIndentByte is the fast path of fillIndent for a single byte prefix and
+// no postfix.
+func fillIndentByte(out, buf []byte, p byte, sol bool) {
+	wrote := 0
+	if sol {
+		out[0] = p
+		wrote++
+	}
+	for {
+		x := bytes.IndexByte(buf, '\n')
+		if x < 0 || x == len(buf)-1 {
+			copy(out[wrote:], buf)
+			return
+		}
+		wrote += copy(out[wrote:], buf[:x+1])
+		out[wrote] = p
+		wrote++
+		buf = buf[x+1:]
+	}
+}
+
+// grow returns dst with room for at least n more bytes.
+func grow(dst []byte, n int) []byte {
+	count(&bytesCopied, int64(n))
+	if cap(dst)-len(dst) >= n {
+		count(&allocsAvoided, 1)
+		return dst
+	}
+	count(&allocs, 1)
+	ndst := make([]byte, len(dst), len(dst)+n)
+	copy(ndst, dst)
+	return ndst
+}
+
+// indentSize returns the length of indent(buf, prefix, postfix, sol).
+func indentSize(buf, prefix, postfix []byte, sol bool) int {
+	if len(buf) == 0 {
+		return 0
+	}
+	// Every newline is preceded by the postfix and, unless it is the last
+	// byte of buf, followed by a prefix.
+	nl := bytes.Count(buf, []byte{'\n'})
+	np := nl
+	if buf[len(buf)-1] == '\n' {
+		np--
+	}
+	if sol {
+		np++
+	}
+	return len(buf) + np*len(prefix) + nl*len(postfix)
 }
 
 // Unwrap unwraps and indenter and returns the underlying io.Writer.  It will
@@ -295,7 +1213,7 @@ func Unwrap(w io.Writer, n int) io.Writer {
 
 	for {
 		if in.p == nil {
-			return in.w
+			return in.s.w
 		}
 		n--
 		if n == 0 {
@@ -304,3 +1222,35 @@ func Unwrap(w io.Writer, n int) io.Writer {
 		in = in.p
 	}
 }
+
+// Unwrap returns the writer that in wraps, which is the writer it is nested in
+// or, for the outermost writer of a chain, the underlying writer.  It is
+// Unwrap(in, 1) and follows the convention of errors.Unwrap so code that
+// unwraps writers with an interface{ Unwrap() io.Writer } assertion can
+// traverse a chain without importing this package.
+func (in *indenter) Unwrap() io.Writer {
+	if in.p != nil {
+		return in.p
+	}
+	return in.s.w
+}
+
+// Chain returns w followed by each of the writers it wraps, ending with the
+// underlying writer of the chain, such that Chain(w)[i] is Unwrap(w, i).  If w
+// was not returned by New, Chain returns just w.  Chain is useful when
+// debugging nested writers:
+//
+//	for i, w := range indent.Chain(w) {
+//		fmt.Printf("%d: %T\n", i, w)
+//	}
+func Chain(w io.Writer) []io.Writer {
+	chain := []io.Writer{w}
+	in, ok := w.(*indenter)
+	if !ok {
+		return chain
+	}
+	for ; in.p != nil; in = in.p {
+		chain = append(chain, in.p)
+	}
+	return append(chain, in.s.w)
+}
@@ -18,9 +18,13 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"runtime"
 	"runtime/debug"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"text/tabwriter"
 )
 
 func dup(s string) string {
@@ -31,9 +35,11 @@ func dup(s string) string {
 var heapMemory [100][]byte
 
 func TestGC(t *testing.T) {
-	// Turning off for now.
-	return
-
+	// The slices s2b makes from strings must stay valid across collections.
+	// Each round collects the heap, which makes this slow.
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
 	input := []string{
 		"abc",
 		"def",
@@ -59,11 +65,11 @@ func TestGC(t *testing.T) {
 
 func TestIndent(t *testing.T) {
 	for _, tt := range []struct {
-		prefix string
+		prefix  string
 		postfix string
-		sol    bool
-		in     string
-		out    string
+		sol     bool
+		in      string
+		out     string
 	}{
 		{},
 		{sol: true},
@@ -127,7 +133,7 @@ func TestNewConsolidating(t *testing.T) {
 	if string(w2.prefix) != "--++" {
 		t.Errorf("Got prefix %q, want %q", w2.prefix, "--++")
 	}
-	if w2.w != w {
+	if w2.s.w != w {
 		t.Error("w2 did not inherit the io.Writer")
 	}
 }
@@ -300,10 +306,10 @@ func TestUnwrap(t *testing.T) {
 	}
 	fmt.Fprintln(w, "line 1")
 
-	w1 := New(w, "1>");
+	w1 := New(w, "1>")
 	fmt.Fprintln(w1, "line 2")
 
-	w2 := New(w1, "2>");
+	w2 := New(w1, "2>")
 	fmt.Fprintln(w2, "line 3")
 
 	if uw := Unwrap(w2, 0); uw != w2 {
@@ -326,7 +332,7 @@ line 1
 1>2>line 5
 `[1:]
 	if got := buf.String(); got != want {
-		t.Errorf("Mixing wrappers on newlines got:\n%s\nwant:\n%s",got, want)
+		t.Errorf("Mixing wrappers on newlines got:\n%s\nwant:\n%s", got, want)
 	}
 }
 
@@ -471,6 +477,53 @@ func TestReturn(t *testing.T) {
 	}
 }
 
+func TestChain(t *testing.T) {
+	var buf bytes.Buffer
+	w1 := New(&buf, "a")
+	w2 := New(w1, "b")
+	w3 := New(w2, "c")
+	for _, w := range []io.Writer{&buf, w1, w2, w3} {
+		chain := Chain(w)
+		for i, cw := range chain {
+			if u := Unwrap(w, i); u != cw {
+				t.Errorf("Chain(%T)[%d] is not Unwrap(w, %d)", w, i, i)
+			}
+		}
+		if last := chain[len(chain)-1]; last != &buf {
+			t.Errorf("chain ends with %T, want the underlying writer", last)
+		}
+	}
+	if n := len(Chain(w3)); n != 4 {
+		t.Errorf("got a chain of %d writers, want 4", n)
+	}
+}
+
+func TestUnwrapMethod(t *testing.T) {
+	type unwrapper interface {
+		Unwrap() io.Writer
+	}
+	var buf bytes.Buffer
+	w := New(New(&buf, "a"), "b")
+	var got []io.Writer
+	for {
+		got = append(got, w)
+		u, ok := w.(unwrapper)
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	want := Chain(got[0])
+	if len(got) != len(want) {
+		t.Fatalf("unwrapped %d writers, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("writer %d is %T, want %T", i, got[i], want[i])
+		}
+	}
+}
+
 var tprefix = "abcd"
 
 func BenchmarkS2B(b *testing.B) {
@@ -522,3 +575,594 @@ func BenchmarkCopy100000(b *testing.B) {
 		n += len([]byte(prefix100000))
 	}
 }
+
+func TestWriteAll(t *testing.T) {
+	for _, bufs := range [][]string{
+		{},
+		{""},
+		{"ab"},
+		{"ab", "", "cd\n"},
+		{"a\n", "b\n", "c"},
+		{"a", "\n", "\nb\nc\n"},
+	} {
+		var want bytes.Buffer
+		w := New(&want, "> ")
+		var nb [][]byte
+		n := 0
+		for _, s := range bufs {
+			w.Write([]byte(s))
+			nb = append(nb, []byte(s))
+			n += len(s)
+		}
+
+		var got bytes.Buffer
+		fw := &countingWriter{w: &got}
+		w = New(fw, "> ")
+		r, err := w.(*indenter).WriteAll(nb...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r != n {
+			t.Errorf("%q: WriteAll returned %d, want %d", bufs, r, n)
+		}
+		if got.String() != want.String() {
+			t.Errorf("%q: got %q, want %q", bufs, got.String(), want.String())
+		}
+		if n > 0 && fw.writes != 1 {
+			t.Errorf("%q: WriteAll did %d writes, want 1", bufs, fw.writes)
+		}
+	}
+
+	// A short write must report the same count as Write.
+	input := "abc\ndef\ngh"
+	for max := 0; max < 16; max++ {
+		w := New(&fakeWriter{left: max}, "--")
+		want, _ := w.Write([]byte(input))
+		w = New(&fakeWriter{left: max}, "--")
+		got, err := w.(*indenter).WriteAll([]byte("ab"), []byte("c\nd"), []byte("ef\ngh"))
+		if got != want {
+			t.Errorf("max %d: WriteAll returned %d, want %d (%v)", max, got, want, err)
+		}
+	}
+}
+
+type countingWriter struct {
+	w      io.Writer
+	writes int
+}
+
+func (c *countingWriter) Write(buf []byte) (int, error) {
+	c.writes++
+	return c.w.Write(buf)
+}
+
+var benchInput = []byte(strings.Repeat("a short line\n\tan indented line\n\n", 1000))
+
+func benchmarkBytes(b *testing.B, prefix string) {
+	p := []byte(prefix)
+	b.SetBytes(int64(len(benchInput)))
+	for i := 0; i < b.N; i++ {
+		Bytes(p, benchInput)
+	}
+}
+
+func BenchmarkBytesTab(b *testing.B)     { benchmarkBytes(b, "\t") }
+func BenchmarkBytesTwoTabs(b *testing.B) { benchmarkBytes(b, "\t\t") }
+func BenchmarkBytesLong(b *testing.B)    { benchmarkBytes(b, "// comment: ") }
+
+func TestIndentSingleByte(t *testing.T) {
+	for _, in := range []string{"a", "\n", "\n\n", "ab\n", "ab\ncd", "ab\ncd\n", "\nab\n\ncd"} {
+		for _, sol := range []bool{false, true} {
+			// Build the expected result the slow way.
+			want := ""
+			s := sol
+			for _, c := range in {
+				if s {
+					want += ">"
+				}
+				want += string(c)
+				s = c == '\n'
+			}
+			if got := string(indent([]byte(in), []byte(">"), nil, sol)); got != want {
+				t.Errorf("indent(%q, \">\", %v) got %q, want %q", in, sol, got, want)
+			}
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	for _, tt := range []struct {
+		in, out []string
+	}{
+		{in: nil, out: []string{}},
+		{in: []string{"a", "", "b"}, out: []string{"> a", "> ", "> b"}},
+		{in: []string{"a\nb", "c\n"}, out: []string{"> a", "> b", "> c"}},
+		{in: []string{"\n\n"}, out: []string{"> ", "> "}},
+	} {
+		got := Lines("> ", tt.in)
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.out) {
+			t.Errorf("Lines(%q) got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	for _, tt := range []struct {
+		dst, prefix, in string
+		out             string
+	}{
+		{},
+		{dst: "x", prefix: "> ", out: "x"},
+		{dst: "x", in: "a\nb", out: "xa\nb"},
+		{prefix: "> ", in: "a\nb\n", out: "> a\n> b\n"},
+		{dst: "x\n", prefix: "> ", in: "a\nb", out: "x\n> a\n> b"},
+	} {
+		if got := Append([]byte(tt.dst), []byte(tt.prefix), []byte(tt.in)); string(got) != tt.out {
+			t.Errorf("Append(%q, %q, %q) got %q, want %q", tt.dst, tt.prefix, tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestAppendAllocs(t *testing.T) {
+	buf := make([]byte, 0, 2*len(benchInput))
+	prefix := []byte("\t")
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = Append(buf[:0], prefix, benchInput)
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
+
+func TestWriteIndentedTo(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		in     string
+		left   int
+		out    string
+		err    error
+	}{
+		{prefix: "> ", in: "", out: ""},
+		{prefix: "> ", in: "a", out: "> a"},
+		{prefix: "> ", in: "a\nb\n", out: "> a\n> b\n"},
+		{prefix: "> ", in: "a\n\nb", out: "> a\n> \n> b"},
+		{prefix: "", in: "a\nb\n", out: "a\nb\n"},
+		{prefix: "> ", in: "a\nb\n", left: 5, out: "> a\n>", err: io.EOF},
+	} {
+		var w io.Writer
+		var buf bytes.Buffer
+		fw := &fakeWriter{left: tt.left}
+		if tt.left > 0 {
+			w = fw
+		} else {
+			w = &buf
+		}
+		n, err := WriteIndentedTo(w, tt.prefix, []byte(tt.in))
+		if tt.left > 0 {
+			buf = fw.buf
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("WriteIndentedTo(%q, %q) got %q, want %q", tt.prefix, tt.in, got, tt.out)
+		}
+		if n != int64(len(tt.out)) {
+			t.Errorf("WriteIndentedTo(%q, %q) got n %d, want %d", tt.prefix, tt.in, n, len(tt.out))
+		}
+		if err != tt.err {
+			t.Errorf("WriteIndentedTo(%q, %q) got error %v, want %v", tt.prefix, tt.in, err, tt.err)
+		}
+	}
+}
+
+func TestWriteIndentedToAllocs(t *testing.T) {
+	w := &countingWriter{w: ioutil.Discard}
+	allocs := testing.AllocsPerRun(100, func() {
+		WriteIndentedTo(w, "\t", benchInput)
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	in := strings.Repeat("abc\ndefgh\n\n", readChunk/5)
+	want := String("> ", in)
+	for _, r := range []io.Reader{
+		strings.NewReader(in),
+		iotest.HalfReader(strings.NewReader(in)),
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ")
+		n, err := w.(io.ReaderFrom).ReadFrom(r)
+		if err != nil {
+			t.Errorf("ReadFrom: %v", err)
+		}
+		if n != int64(len(in)) {
+			t.Errorf("ReadFrom got %d, want %d", n, len(in))
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("ReadFrom wrote %d bytes, want %d", len(got), len(want))
+		}
+	}
+
+	var buf bytes.Buffer
+	r := io.MultiReader(strings.NewReader("a\nb"), iotest.TimeoutReader(strings.NewReader("c")))
+	n, err := io.Copy(New(&buf, "> "), r)
+	if err != iotest.ErrTimeout {
+		t.Errorf("got error %v, want %v", err, iotest.ErrTimeout)
+	}
+	if got, want := buf.String(), "> a\n> bc"; n != 4 || got != want {
+		t.Errorf("got %d, %q, want 4, %q", n, got, want)
+	}
+}
+
+func TestReadFromAllocs(t *testing.T) {
+	in := bytes.Repeat(benchInput, 8)
+	w := New(ioutil.Discard, "\t")
+	allocs := testing.AllocsPerRun(10, func() {
+		w.(io.ReaderFrom).ReadFrom(bytes.NewReader(in))
+	})
+	// The read buffer, the output buffer, and the deferred function.
+	if allocs > 3 {
+		t.Errorf("got %v allocations, want at most 3", allocs)
+	}
+}
+
+// syncWriter records calls to Flush and Sync.
+type syncWriter struct {
+	bytes.Buffer
+	calls []string
+}
+
+func (s *syncWriter) Flush() error {
+	s.calls = append(s.calls, "Flush "+s.String())
+	return nil
+}
+
+func (s *syncWriter) Sync() error {
+	s.calls = append(s.calls, "Sync "+s.String())
+	return nil
+}
+
+func TestFlushSync(t *testing.T) {
+	type syncer interface {
+		Flush() error
+		Sync() error
+	}
+	sw := &syncWriter{}
+	w := New(sw, "> ", WithBuffering(64))
+	io.WriteString(w, "a\n")
+	if err := w.(syncer).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "b\n")
+	if err := w.(syncer).Sync(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Flush > a\n",
+		"Flush > a\n> b\n",
+		"Sync > a\n> b\n",
+	}
+	if got := sw.calls; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got calls %q, want %q", got, want)
+	}
+
+	// Writers without Flush or Sync methods are fine.
+	var buf bytes.Buffer
+	w = New(&buf, "> ")
+	if err := w.(syncer).Sync(); err != nil {
+		t.Errorf("Sync returned %v", err)
+	}
+}
+
+func TestFlushNested(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 1, ' ', 0)
+	w := New(New(New(tw, "> "), "  "), "- ")
+	io.WriteString(w, "a\tb\nccc\td\n")
+	if buf.Len() != 0 {
+		t.Fatalf("tabwriter wrote %q before Flush", buf.String())
+	}
+	if err := w.(interface{ Flush() error }).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), ">   - a   b\n>   - ccc d\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestStringAt(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		sol bool
+		out string
+	}{
+		{in: "", sol: false, out: ""},
+		{in: "a", sol: true, out: "> a"},
+		{in: "a", sol: false, out: "a"},
+		{in: "a\nb\n", sol: false, out: "a\n> b\n"},
+		{in: "\nb", sol: false, out: "\n> b"},
+		{in: "a\nb\n", sol: true, out: "> a\n> b\n"},
+	} {
+		if got := StringAt("> ", tt.in, tt.sol); got != tt.out {
+			t.Errorf("StringAt(%q, %v) got %q, want %q", tt.in, tt.sol, got, tt.out)
+		}
+		if got := string(BytesAt([]byte("> "), []byte(tt.in), tt.sol)); got != tt.out {
+			t.Errorf("BytesAt(%q, %v) got %q, want %q", tt.in, tt.sol, got, tt.out)
+		}
+		if got := StringAt("> ", tt.in, tt.sol, WithParallel(1)); got != tt.out {
+			t.Errorf("StringAt(%q, %v, WithParallel) got %q, want %q", tt.in, tt.sol, got, tt.out)
+		}
+	}
+}
+
+func TestStringOptions(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		prefix string
+		in     string
+		opts   []Option
+		out    string
+	}{
+		{name: "drop cr", prefix: "> ", in: "a\r\nb\n", opts: []Option{WithDropCR()}, out: "> a\n> b\n"},
+		{name: "suffix", prefix: "> ", in: "a\nb\n", opts: []Option{WithSuffix(" <")}, out: "> a <\n> b <\n"},
+		{name: "empty prefix", in: "a\nb\n", opts: []Option{WithSuffix(" <")}, out: "a <\nb <\n"},
+		{name: "trim", prefix: "> ", in: "a  \n\n", opts: []Option{WithTrimTrailingSpace()}, out: "> a\n>\n"},
+		{name: "final newline", prefix: "> ", in: "a", opts: []Option{WithFinalNewline()}, out: "> a\n"},
+		{name: "empty input", prefix: "> ", opts: []Option{WithFinalNewline()}, out: ""},
+		{name: "max output", prefix: "> ", in: "a\nb\n", opts: []Option{WithMaxOutput(6)}, out: "> a\n> "},
+	} {
+		if got := String(tt.prefix, tt.in, tt.opts...); got != tt.out {
+			t.Errorf("%s: String got %q, want %q", tt.name, got, tt.out)
+		}
+		if got := string(Bytes([]byte(tt.prefix), []byte(tt.in), tt.opts...)); got != tt.out {
+			t.Errorf("%s: Bytes got %q, want %q", tt.name, got, tt.out)
+		}
+	}
+}
+
+func TestOneShot(t *testing.T) {
+	EnableMetrics(true)
+	defer EnableMetrics(false)
+
+	// Without transforming options the input is indented straight into
+	// the result.
+	ResetMetrics()
+	out, err := oneShot([]byte("> "), []byte("a\nb\n"), true, []Option{WithParallel(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(out), "> a\n> b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if m := ReadMetrics(); m.Allocs != 1 || m.Writes != 0 {
+		t.Errorf("got %d allocations and %d writes, want 1 and 0", m.Allocs, m.Writes)
+	}
+
+	if _, err := oneShot([]byte("> "), []byte("a\nb\n"), true, []Option{WithMaxOutput(6)}); err != ErrMaxOutput {
+		t.Errorf("got error %v, want %v", err, ErrMaxOutput)
+	}
+}
+
+func TestGrow(t *testing.T) {
+	EnableMetrics(true)
+	defer EnableMetrics(false)
+
+	for _, opts := range [][]Option{nil, {WithReusedBuffer()}, {WithDropCR()}} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", opts...)
+		in := w.(*indenter)
+		in.Grow(64)
+		ResetMetrics()
+		io.WriteString(w, "line 1\r\nline 2\n")
+		want := "> line 1\r\n> line 2\n"
+		if in.s.opt != nil && in.s.opt.dropCR {
+			want = "> line 1\n> line 2\n"
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("%d options: got %q, want %q", len(opts), got, want)
+		}
+		// Only the writers that are not transforming count allocations.
+		if m := ReadMetrics(); m.Allocs != 0 {
+			t.Errorf("%d options: Write allocated %d buffers after Grow", len(opts), m.Allocs)
+		}
+		if !in.s.reuse && in.s.opt != nil && in.s.opt.buf != nil {
+			t.Errorf("%d options: buffer retained after Write", len(opts))
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Grow(-1) did not panic")
+		}
+	}()
+	New(ioutil.Discard, "> ").(*indenter).Grow(-1)
+}
+
+func TestPrefixDepth(t *testing.T) {
+	type prefixDepth interface {
+		Prefix() string
+		Depth() int
+	}
+	w1 := New(ioutil.Discard, "> ")
+	w2 := New(w1, "\t", WithTabStop(4))
+	w3 := New(w2, "| ")
+	for _, tt := range []struct {
+		w      io.Writer
+		prefix string
+		depth  int
+	}{
+		{w1, "> ", 1},
+		{w2, ">   ", 2},
+		{w3, ">   | ", 3},
+	} {
+		pd := tt.w.(prefixDepth)
+		if got := pd.Prefix(); got != tt.prefix {
+			t.Errorf("got prefix %q, want %q", got, tt.prefix)
+		}
+		if got := pd.Depth(); got != tt.depth {
+			t.Errorf("%q: got depth %d, want %d", tt.prefix, got, tt.depth)
+		}
+	}
+}
+
+func TestNewAt(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		sol    bool
+		in     string
+		out    string
+	}{
+		{prefix: "> ", sol: true, in: "a\nb\n", out: "x: > a\n> b\n"},
+		{prefix: "> ", sol: false, in: "a\nb\n", out: "x: a\n> b\n"},
+		{prefix: "", sol: false, in: "a\nb\n", out: "x: a\nb\n"},
+	} {
+		var buf bytes.Buffer
+		buf.WriteString("x: ")
+		io.WriteString(NewAt(&buf, tt.prefix, tt.sol), tt.in)
+		if got := buf.String(); got != tt.out {
+			t.Errorf("NewAt(%q, %v) got %q, want %q", tt.prefix, tt.sol, got, tt.out)
+		}
+	}
+}
+
+func TestAtLineStart(t *testing.T) {
+	type atLineStart interface {
+		AtLineStart() bool
+	}
+	w := New(ioutil.Discard, "> ")
+	w2 := New(w, "  ")
+	for _, tt := range []struct {
+		w   io.Writer
+		in  string
+		sol bool
+	}{
+		{w, "", true},
+		{w, "a", false},
+		{w2, "b\n", true},
+		{w, "c\nd", false},
+		{w2, "\n", true},
+	} {
+		io.WriteString(tt.w, tt.in)
+		if got := w.(atLineStart).AtLineStart(); got != tt.sol {
+			t.Errorf("after %q got %v, want %v", tt.in, got, tt.sol)
+		}
+	}
+}
+
+func TestDisable(t *testing.T) {
+	type disabler interface {
+		Disable()
+		Enable()
+	}
+	var buf bytes.Buffer
+	w := New(&buf, "> ")
+	w2 := New(w, "  ")
+	io.WriteString(w2, "a\n")
+	w.(disabler).Disable()
+	io.WriteString(w2, "+--+\n|  |\n+--")
+	w.(*indenter).WriteAll([]byte("-+"), []byte("\n"))
+	w.(disabler).Enable()
+	io.WriteString(w2, "b\n")
+	w.(disabler).Disable()
+	io.WriteString(w2, "raw ")
+	w.(disabler).Enable()
+	io.WriteString(w2, "c\n")
+	want := ">   a\n+--+\n|  |\n+---+\n>   b\nraw c\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRaw(t *testing.T) {
+	type rawer interface {
+		Raw(func(io.Writer))
+	}
+	var buf bytes.Buffer
+	w := New(&buf, "> ")
+	io.WriteString(w, "a\n")
+	w.(rawer).Raw(func(w io.Writer) {
+		io.WriteString(w, "\x1b[2J")
+		io.WriteString(w, "+-+\n| |\n")
+	})
+	io.WriteString(w, "b\n")
+	w.(rawer).Raw(func(w io.Writer) {
+		io.WriteString(w, "raw ")
+	})
+	io.WriteString(w, "c\nd\n")
+	want := "> a\n\x1b[2J+-+\n| |\n> b\nraw c\n> d\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestStats(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		opts  []Option
+		write func(w io.Writer)
+		want  Stats
+	}{
+		{
+			name: "write",
+			write: func(w io.Writer) {
+				io.WriteString(w, "a\nb")
+				io.WriteString(w, "c\n\n")
+				io.WriteString(New(w, "  "), "d\ne")
+			},
+			want: Stats{Lines: 4, Prefixes: 5, Bytes: 23},
+		},
+		{
+			name: "write all",
+			write: func(w io.Writer) {
+				w.(*indenter).WriteAll([]byte("a\n"), []byte("b"), []byte("c\n"))
+			},
+			want: Stats{Lines: 2, Prefixes: 2, Bytes: 9},
+		},
+		{
+			name: "transform",
+			opts: []Option{WithDropCR()},
+			write: func(w io.Writer) {
+				io.WriteString(w, "a\r\nb\r\n")
+			},
+			want: Stats{Lines: 2, Prefixes: 2, Bytes: 8},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := New(ioutil.Discard, "> ", tt.opts...)
+			tt.write(w)
+			if got := w.(interface{ Stats() Stats }).Stats(); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Only what was written is counted.
+	w := New(&fakeWriter{left: 6}, "> ")
+	io.WriteString(w, "ab\ncd\n")
+	if got, want := w.(*indenter).Stats(), (Stats{Lines: 1, Prefixes: 1, Bytes: 6}); got != want {
+		t.Errorf("short write got %+v, want %+v", got, want)
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithBuffering(64)}} {
+		var b1, b2 bytes.Buffer
+		w1 := New(&b1, "1> ", opts...)
+		w2 := New(w1, "2> ")
+		io.WriteString(w2, "a\nb")
+		w2.(interface{ SetOutput(io.Writer) }).SetOutput(&b2)
+		io.WriteString(w2, "c\n")
+		io.WriteString(w1, "d\n")
+		w1.(interface{ Flush() error }).Flush()
+		if got, want := b1.String(), "1> 2> a\n1> 2> b"; got != want {
+			t.Errorf("%d options: old writer got %q, want %q", len(opts), got, want)
+		}
+		if got, want := b2.String(), "c\n1> d\n"; got != want {
+			t.Errorf("%d options: new writer got %q, want %q", len(opts), got, want)
+		}
+		if Unwrap(w2, 2) != &b2 {
+			t.Errorf("%d options: Unwrap did not return the new writer", len(opts))
+		}
+	}
+}
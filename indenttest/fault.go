@@ -0,0 +1,76 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indenttest
+
+import "io"
+
+// A FaultWriter is an io.Writer that fails in configurable ways.  It is used
+// to test the handling of errors and short writes by code that writes to, or
+// through, an indenter.  The bytes a FaultWriter accepts are written to W.
+// The zero value of a FaultWriter accepts and discards everything written to
+// it.
+type FaultWriter struct {
+	W io.Writer // where accepted bytes are written, may be nil
+
+	// FailAfter, if positive, is the total number of bytes accepted.  The
+	// write that would exceed it accepts what fits and then it, and every
+	// later write, returns Err.
+	FailAfter int
+
+	// FailEvery, if positive, causes every FailEvery'th call to Write to
+	// accept nothing and return Err.
+	FailEvery int
+
+	// MaxWrite, if positive, is the most bytes a single Write accepts.  A
+	// longer Write accepts MaxWrite bytes and returns io.ErrShortWrite.
+	MaxWrite int
+
+	// Err is the error returned by failing writes.  If nil, io.EOF is used.
+	Err error
+
+	Written int // total number of bytes accepted
+	Writes  int // number of calls to Write
+}
+
+// Write implements io.Writer.
+func (f *FaultWriter) Write(buf []byte) (int, error) {
+	f.Writes++
+	ferr := f.Err
+	if ferr == nil {
+		ferr = io.EOF
+	}
+	if f.FailEvery > 0 && f.Writes%f.FailEvery == 0 {
+		return 0, ferr
+	}
+	var err error
+	if f.MaxWrite > 0 && len(buf) > f.MaxWrite {
+		buf = buf[:f.MaxWrite]
+		err = io.ErrShortWrite
+	}
+	if f.FailAfter > 0 && f.Written+len(buf) > f.FailAfter {
+		buf = buf[:f.FailAfter-f.Written]
+		err = ferr
+	}
+	n := len(buf)
+	if f.W != nil && n > 0 {
+		var werr error
+		n, werr = f.W.Write(buf)
+		if werr != nil {
+			err = werr
+		}
+	}
+	f.Written += n
+	return n, err
+}
@@ -0,0 +1,95 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indenttest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/pborman/indent"
+)
+
+func TestFaultWriter(t *testing.T) {
+	errFail := errors.New("fail")
+	type result struct {
+		n   int
+		err error
+	}
+	for _, tt := range []struct {
+		name   string
+		f      FaultWriter
+		writes []string
+		want   []result
+		out    string
+	}{{
+		name:   "zero",
+		writes: []string{"abc", "def"},
+		want:   []result{{3, nil}, {3, nil}},
+		out:    "abcdef",
+	}, {
+		name:   "fail after",
+		f:      FaultWriter{FailAfter: 4},
+		writes: []string{"abc", "def", "ghi"},
+		want:   []result{{3, nil}, {1, io.EOF}, {0, io.EOF}},
+		out:    "abcd",
+	}, {
+		name:   "fail every",
+		f:      FaultWriter{FailEvery: 2, Err: errFail},
+		writes: []string{"abc", "def", "ghi", "jkl"},
+		want:   []result{{3, nil}, {0, errFail}, {3, nil}, {0, errFail}},
+		out:    "abcghi",
+	}, {
+		name:   "max write",
+		f:      FaultWriter{MaxWrite: 2},
+		writes: []string{"abc", "de"},
+		want:   []result{{2, io.ErrShortWrite}, {2, nil}},
+		out:    "abde",
+	}} {
+		var buf bytes.Buffer
+		f := tt.f
+		f.W = &buf
+		for i, w := range tt.writes {
+			n, err := f.Write([]byte(w))
+			if got := (result{n, err}); got != tt.want[i] {
+				t.Errorf("%s: write %d got %v, want %v", tt.name, i, got, tt.want[i])
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.out)
+		}
+		if f.Written != len(tt.out) {
+			t.Errorf("%s: got Written %d, want %d", tt.name, f.Written, len(tt.out))
+		}
+		if f.Writes != len(tt.writes) {
+			t.Errorf("%s: got Writes %d, want %d", tt.name, f.Writes, len(tt.writes))
+		}
+	}
+}
+
+func TestFaultWriterIndenter(t *testing.T) {
+	// The indenter must report how much of its input made it to the
+	// FaultWriter.
+	var buf bytes.Buffer
+	w := indent.New(&FaultWriter{W: &buf, FailAfter: 7}, "> ")
+	n, err := io.WriteString(w, "abc\ndef\n")
+	if n != 4 || err != io.EOF {
+		t.Errorf("got %d, %v, want 4, %v", n, err, io.EOF)
+	}
+	if got, want := buf.String(), "> abc\n>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indenttest
+
+import (
+	"strings"
+	"testing"
+)
+
+// tabWidth is the width of a tab when measuring indentation.
+const tabWidth = 8
+
+// Normalize returns s with insignificant whitespace differences removed so it
+// may be compared to golden output produced on another platform or by another
+// version of a generator.  Normalize:
+//
+//   - converts "\r\n" line endings to "\n"
+//   - removes trailing spaces and tabs from each line
+//   - collapses runs of blank lines into a single blank line
+//   - removes blank lines from the start and end of s
+//   - ends s with a single newline, unless s is empty
+//   - rewrites the indentation of each line as one tab for each unit of
+//     indentation, where the unit is the smallest indentation in s
+//
+// When measuring indentation a tab advances to the next multiple of 8
+// columns.  Indentation that is not a multiple of the unit is kept as
+// trailing spaces, so
+//
+//	"a\n  b\n    c\n     d\n"
+//
+// is normalized to
+//
+//	"a\n\tb\n\t\tc\n\t\t d\n"
+func Normalize(s string) string {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	unit := 0
+	widths := make([]int, len(lines))
+	for i, line := range lines {
+		widths[i] = width(line)
+		if widths[i] > 0 && (unit == 0 || widths[i] < unit) {
+			unit = widths[i]
+		}
+	}
+
+	var b strings.Builder
+	for i, line := range lines {
+		if w := widths[i]; w > 0 {
+			b.WriteString(strings.Repeat("\t", w/unit))
+			b.WriteString(strings.Repeat(" ", w%unit))
+		}
+		b.WriteString(strings.TrimLeft(line, " \t"))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// width returns the width of the indentation of line.
+func width(line string) int {
+	w := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			w++
+		case '\t':
+			w += tabWidth - w%tabWidth
+		default:
+			return w
+		}
+	}
+	return w
+}
+
+// AssertNormalized reports an error to t if got and want are not the same
+// after both have been passed through Normalize.
+func AssertNormalized(t testing.TB, got, want string) bool {
+	t.Helper()
+	got, want = Normalize(got), Normalize(want)
+	if got == want {
+		return true
+	}
+	t.Errorf("normalized output does not match (- want, + got):\n%s", Diff(got, want))
+	return false
+}
@@ -0,0 +1,58 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indenttest
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		out string
+	}{
+		{in: "", out: ""},
+		{in: "\n\n", out: ""},
+		{in: "a", out: "a\n"},
+		{in: "a  \nb\t\n", out: "a\nb\n"},
+		{in: "a\r\nb\r\n", out: "a\nb\n"},
+		{in: "\n\na\n\n\n\nb\n\n", out: "a\n\nb\n"},
+		{in: "a\n  \n\t\nb\n", out: "a\n\nb\n"},
+		{in: "a\n  b\n    c\n     d\n", out: "a\n\tb\n\t\tc\n\t\t d\n"},
+		{in: "a\n    b\n        c\n", out: "a\n\tb\n\t\tc\n"},
+		{in: "a\n\tb\n\t\tc\n", out: "a\n\tb\n\t\tc\n"},
+		{in: "  a\n  b\n", out: "\ta\n\tb\n"},
+		{in: "a\n    \tb\n", out: "a\n\tb\n"},
+	} {
+		if got := Normalize(tt.in); got != tt.out {
+			t.Errorf("Normalize(%q) got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestAssertNormalized(t *testing.T) {
+	for _, tt := range []struct {
+		got  string
+		want string
+		ok   bool
+	}{
+		{got: "a\n  b\n", want: "a\n\tb\n", ok: true},
+		{got: "a\r\n    b  \r\n\r\n", want: "a\n\tb\n", ok: true},
+		{got: "a\nb\n", want: "a\n\tb\n"},
+	} {
+		f := &fakeT{}
+		if ok := AssertNormalized(f, tt.got, tt.want); ok != tt.ok {
+			t.Errorf("AssertNormalized(%q, %q) got %v, want %v: %q", tt.got, tt.want, ok, tt.ok, f.errors)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package indenttest provides helpers for testing code that produces indented
+// output, such as code using the indent package.  When output does not match,
+// the helpers report the differences a line at a time with whitespace made
+// visible, so a wrong prefix or a stray trailing space is easy to spot.
+package indenttest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pborman/indent"
+)
+
+// AssertIndented reports an error to t if got is not want with each line of
+// want prefixed by prefix.  An empty prefix compares got to want.
+func AssertIndented(t testing.TB, prefix, got, want string) bool {
+	t.Helper()
+	want = indent.String(prefix, want)
+	if got == want {
+		return true
+	}
+	t.Errorf("indented output does not match (- want, + got):\n%s", Diff(got, want))
+	return false
+}
+
+// Diff returns a line aligned listing of got and want.  Lines that are the
+// same are preceded by two spaces.  Lines that differ are shown as a pair
+// with the wanted line preceded by "- " and the line we got preceded by "+ ".
+// Spaces are displayed as '·', tabs as '→', and the end of each line as '⏎',
+// so differences in whitespace are visible.  Diff returns "" if got and want
+// are the same.
+func Diff(got, want string) string {
+	if got == want {
+		return ""
+	}
+	gl := lines(got)
+	wl := lines(want)
+	n := len(gl)
+	if len(wl) > n {
+		n = len(wl)
+	}
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		var g, w string
+		gok := i < len(gl)
+		wok := i < len(wl)
+		if gok {
+			g = gl[i]
+		}
+		if wok {
+			w = wl[i]
+		}
+		if gok && wok && g == w {
+			fmt.Fprintf(&b, "  %4d %s\n", i+1, Visible(g))
+			continue
+		}
+		if wok {
+			fmt.Fprintf(&b, "- %4d %s\n", i+1, Visible(w))
+		}
+		if gok {
+			fmt.Fprintf(&b, "+ %4d %s\n", i+1, Visible(g))
+		}
+	}
+	return b.String()
+}
+
+// Visible returns s with spaces displayed as '·', tabs as '→', carriage
+// returns as '␍', and newlines as '⏎'.
+func Visible(s string) string {
+	return visible.Replace(s)
+}
+
+var visible = strings.NewReplacer(" ", "·", "\t", "→", "\r", "␍", "\n", "⏎")
+
+// lines splits s into lines, each retaining its newline.
+func lines(s string) []string {
+	l := strings.SplitAfter(s, "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
@@ -0,0 +1,91 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indenttest
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fakeT records the errors reported to it.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertIndented(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		got    string
+		want   string
+		ok     bool
+	}{
+		{prefix: "> ", got: "> a\n> b\n", want: "a\nb\n", ok: true},
+		{prefix: "", got: "a\nb", want: "a\nb", ok: true},
+		{prefix: "> ", got: ">a\n> b\n", want: "a\nb\n"},
+		{prefix: "\t", got: "\ta\n", want: "a"},
+	} {
+		f := &fakeT{}
+		if ok := AssertIndented(f, tt.prefix, tt.got, tt.want); ok != tt.ok {
+			t.Errorf("AssertIndented(%q, %q, %q) got %v, want %v", tt.prefix, tt.got, tt.want, ok, tt.ok)
+		}
+		if got := len(f.errors) == 0; got != tt.ok {
+			t.Errorf("AssertIndented(%q, %q, %q) reported %q", tt.prefix, tt.got, tt.want, f.errors)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	for _, tt := range []struct {
+		got  string
+		want string
+		diff string
+	}{
+		{got: "a\n", want: "a\n", diff: ""},
+		{
+			got:  "> a\n>  b\n",
+			want: "> a\n> b\n",
+			diff: "" +
+				"     1 >·a⏎\n" +
+				"-    2 >·b⏎\n" +
+				"+    2 >··b⏎\n",
+		},
+		{
+			got:  "\ta",
+			want: "\ta\n\tb\n",
+			diff: "" +
+				"-    1 →a⏎\n" +
+				"+    1 →a\n" +
+				"-    2 →b⏎\n",
+		},
+		{
+			got:  "a\nb\r\n",
+			want: "a\n",
+			diff: "" +
+				"     1 a⏎\n" +
+				"+    2 b␍⏎\n",
+		},
+	} {
+		if got := Diff(tt.got, tt.want); got != tt.diff {
+			t.Errorf("Diff(%q, %q) got:\n%s\nwant:\n%s", tt.got, tt.want, got, tt.diff)
+		}
+	}
+}
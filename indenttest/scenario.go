@@ -0,0 +1,136 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indenttest
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+
+	"github.com/pborman/indent"
+)
+
+// A Scenario is a randomly generated script for exercising a chain of nested
+// indenting writers.  A Scenario implements quick.Generator so it may be used
+// as an argument to a function passed to testing/quick's Check:
+//
+//	f := func(s indenttest.Scenario) bool {
+//		var buf bytes.Buffer
+//		if err := s.Run(&buf); err != nil {
+//			return false
+//		}
+//		return buf.String() == s.Want()
+//	}
+//	if err := quick.Check(f, nil); err != nil {
+//		t.Error(err)
+//	}
+type Scenario struct {
+	Prefixes []string // prefix of each writer, outermost first
+	Steps    []Step   // writes to perform, in order
+}
+
+// A Step is a single Write to one of the writers of a Scenario.
+type Step struct {
+	Depth int    // index into Prefixes of the writer to write to
+	Data  string // the data to write
+}
+
+// Pieces used to build random prefixes and text.  Newlines are common so
+// lines are short and writes often split lines.
+var (
+	prefixPieces = []string{" ", "  ", "\t", ">", "// ", "|", "«»"}
+	textPieces   = []string{"a", "bc", "def", " ", "\t", "\n", "\n", "\n\n", "é"}
+)
+
+// RandomPrefix returns a random, non-empty, prefix.
+func RandomPrefix(r *rand.Rand) string {
+	var b strings.Builder
+	for n := 1 + r.Intn(3); n > 0; n-- {
+		b.WriteString(prefixPieces[r.Intn(len(prefixPieces))])
+	}
+	return b.String()
+}
+
+// RandomText returns random text of about size bytes made up of short lines.
+func RandomText(r *rand.Rand, size int) string {
+	var b strings.Builder
+	for b.Len() < size {
+		b.WriteString(textPieces[r.Intn(len(textPieces))])
+	}
+	return b.String()
+}
+
+// NewScenario returns a random Scenario using r.  The size determines the
+// approximate number of steps.
+func NewScenario(r *rand.Rand, size int) Scenario {
+	if size < 1 {
+		size = 1
+	}
+	var s Scenario
+	for n := 1 + r.Intn(4); n > 0; n-- {
+		s.Prefixes = append(s.Prefixes, RandomPrefix(r))
+	}
+	for n := 1 + r.Intn(size); n > 0; n-- {
+		s.Steps = append(s.Steps, Step{
+			Depth: r.Intn(len(s.Prefixes)),
+			Data:  RandomText(r, 1+r.Intn(16)),
+		})
+	}
+	return s
+}
+
+// Generate implements quick.Generator.
+func (Scenario) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(NewScenario(r, size))
+}
+
+// Run creates a chain of writers on top of w, one for each of s's prefixes,
+// and performs s's steps.  Run returns the first error returned by a write.
+func (s Scenario) Run(w io.Writer) error {
+	writers := make([]io.Writer, len(s.Prefixes))
+	for i, p := range s.Prefixes {
+		writers[i] = indent.New(w, p)
+		w = writers[i]
+	}
+	for _, step := range s.Steps {
+		if _, err := io.WriteString(writers[step.Depth], step.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Want returns the output Run should produce.  It is computed by a simple
+// model of a chain of writers: the chain is at the start of a line after it
+// writes a newline, and each byte written at the start of a line is preceded
+// by the combined prefixes of the writer being written to.
+func (s Scenario) Want() string {
+	var b bytes.Buffer
+	sol := true
+	for _, step := range s.Steps {
+		prefix := strings.Join(s.Prefixes[:step.Depth+1], "")
+		for i := 0; i < len(step.Data); i++ {
+			if sol {
+				b.WriteString(prefix)
+			}
+			c := step.Data[i]
+			b.WriteByte(c)
+			sol = c == '\n'
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indenttest
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"testing/quick"
+)
+
+func TestScenarioWant(t *testing.T) {
+	s := Scenario{
+		Prefixes: []string{"1> ", "2> "},
+		Steps: []Step{
+			{Depth: 0, Data: "abc"},
+			{Depth: 1, Data: "123\n456"},
+			{Depth: 0, Data: "def\n"},
+		},
+	}
+	want := "1> abc123\n1> 2> 456def\n"
+	if got := s.Want(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestScenarioQuick(t *testing.T) {
+	f := func(s Scenario) bool {
+		var buf bytes.Buffer
+		if err := s.Run(&buf); err != nil {
+			t.Errorf("%+v: %v", s, err)
+			return false
+		}
+		if got, want := buf.String(), s.Want(); got != want {
+			t.Errorf("%+v:\n%s", s, Diff(got, want))
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewScenario(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		s := NewScenario(r, 10)
+		if len(s.Prefixes) == 0 || len(s.Steps) == 0 || len(s.Steps) > 10 {
+			t.Fatalf("bad scenario: %+v", s)
+		}
+		for _, p := range s.Prefixes {
+			if p == "" {
+				t.Fatalf("empty prefix in %+v", s)
+			}
+		}
+		for _, step := range s.Steps {
+			if step.Depth < 0 || step.Depth >= len(s.Prefixes) || step.Data == "" {
+				t.Fatalf("bad step %+v in %+v", step, s)
+			}
+		}
+	}
+}
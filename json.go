@@ -0,0 +1,153 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// MarshalIndent is like json.MarshalIndent but the text starting each line is
+// provided by level, which is called with the nesting depth of the line.  Top
+// level lines have a depth of 0.  As with json.MarshalIndent, the first line
+// is not prefixed.  For example,
+//
+//	MarshalIndent(v, Levels("  "))
+//
+// is equivalent to json.MarshalIndent(v, "", "  ").
+func MarshalIndent(v interface{}, level func(depth int) string) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(b) * 2)
+	if _, err := NewJSON(&buf, level).Write(b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Levels returns a level function for MarshalIndent and NewJSON.  A line at
+// depth n is started with the first n prefixes concatenated together.  The
+// last prefix is repeated for depths greater than len(prefixes).
+func Levels(prefixes ...string) func(depth int) string {
+	levels := make([]string, len(prefixes)+1)
+	for i, p := range prefixes {
+		levels[i+1] = levels[i] + p
+	}
+	return func(depth int) string {
+		switch {
+		case depth <= 0 || len(prefixes) == 0:
+			return ""
+		case depth < len(levels):
+			return levels[depth]
+		}
+		n := len(prefixes)
+		return levels[n] + strings.Repeat(prefixes[n-1], depth-n)
+	}
+}
+
+// A jsonWriter re-indents a stream of JSON text.
+type jsonWriter struct {
+	w     io.Writer
+	level func(int) string
+	out   outbuf
+	err   error
+	depth int
+	str   bool // we are in a string
+	esc   bool // the previous byte in the string was a backslash
+	open  bool // we just opened an object or array
+}
+
+// NewJSON returns a writer that re-indents the JSON text written to it, such
+// as the output of a json.Encoder, and writes the result to w.  Whitespace
+// outside of strings is replaced, except that newlines separating top level
+// values are preserved.  The text starting each line is provided by level as
+// described by MarshalIndent.  NewJSON does not validate its input.
+func NewJSON(w io.Writer, level func(depth int) string) io.Writer {
+	return &jsonWriter{w: w, level: level}
+}
+
+func (j *jsonWriter) newline() {
+	j.out.addString("\n")
+	j.out.addString(j.level(j.depth))
+}
+
+func (j *jsonWriter) Write(buf []byte) (int, error) {
+	if j.err != nil {
+		return 0, j.err
+	}
+	for _, c := range buf {
+		if j.str {
+			j.out.copyByte(c)
+			switch {
+			case j.esc:
+				j.esc = false
+			case c == '\\':
+				j.esc = true
+			case c == '"':
+				j.str = false
+			}
+			continue
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			if c == '\n' && j.depth == 0 {
+				j.out.copyByte(c)
+			} else {
+				j.out.drop(1)
+			}
+			continue
+		}
+		if j.open {
+			j.open = false
+			if c == '}' || c == ']' {
+				j.depth--
+				j.out.copyByte(c)
+				continue
+			}
+			j.newline()
+		}
+		switch c {
+		case '{', '[':
+			j.out.copyByte(c)
+			j.depth++
+			j.open = true
+		case '}', ']':
+			if j.depth > 0 {
+				j.depth--
+			}
+			j.newline()
+			j.out.copyByte(c)
+		case ',':
+			j.out.copyByte(c)
+			j.newline()
+		case ':':
+			j.out.copyByte(c)
+			j.out.addString(" ")
+		case '"':
+			j.str = true
+			j.out.copyByte(c)
+		default:
+			j.out.copyByte(c)
+		}
+	}
+	n, err := j.out.writeTo(j.w)
+	j.err = err
+	return n, err
+}
@@ -0,0 +1,144 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	for _, tt := range []struct {
+		prefixes []string
+		depth    int
+		out      string
+	}{
+		{depth: 0, out: ""},
+		{depth: 3, out: ""},
+		{prefixes: []string{"  "}, depth: 0, out: ""},
+		{prefixes: []string{"  "}, depth: 1, out: "  "},
+		{prefixes: []string{"  "}, depth: 3, out: "      "},
+		{prefixes: []string{"a", "b"}, depth: -1, out: ""},
+		{prefixes: []string{"a", "b"}, depth: 1, out: "a"},
+		{prefixes: []string{"a", "b"}, depth: 2, out: "ab"},
+		{prefixes: []string{"a", "b"}, depth: 4, out: "abbb"},
+	} {
+		if out := Levels(tt.prefixes...)(tt.depth); out != tt.out {
+			t.Errorf("Levels(%q)(%d) got %q, want %q", tt.prefixes, tt.depth, out, tt.out)
+		}
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	for _, v := range []interface{}{
+		nil,
+		1,
+		"a string with {[,:]} in it",
+		`an "escaped\" string`,
+		[]int{},
+		map[string]int{},
+		[]interface{}{1, "two", []int{}, map[string]int{}, []int{3, 4}},
+		map[string]interface{}{
+			"a": 1,
+			"b": []string{"x", "y"},
+			"c": map[string]interface{}{
+				"d": nil,
+				"e": []interface{}{map[string]bool{"f": true}},
+			},
+		},
+	} {
+		want, err := json.MarshalIndent(v, ">", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := MarshalIndent(v, func(depth int) string {
+			return ">" + Levels("  ")(depth)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("MarshalIndent(%v) got:\n%s\nwant:\n%s", v, got, want)
+		}
+	}
+}
+
+func TestMarshalIndentLevels(t *testing.T) {
+	v := map[string]interface{}{
+		"a": []int{1, 2},
+		"b": map[string]int{"c": 3},
+	}
+	got, err := MarshalIndent(v, Levels("- ", "  "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `
+{
+- "a": [
+-   1,
+-   2
+- ],
+- "b": {
+-   "c": 3
+- }
+}`[1:]
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := MarshalIndent(func() {}, Levels("  ")); err == nil {
+		t.Errorf("MarshalIndent of a func did not fail")
+	}
+}
+
+func TestNewJSON(t *testing.T) {
+	input := "{\"a\": [1,\n 2], \"b\" : {}}\n[ ]\n\"x\"\n"
+	want := "{\n\t\"a\": [\n\t\t1,\n\t\t2\n\t],\n\t\"b\": {}\n}\n[]\n\"x\"\n"
+
+	var buf bytes.Buffer
+	if _, err := NewJSON(&buf, Levels("\t")).Write([]byte(input)); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Writing a byte at a time must produce the same results.
+	buf.Reset()
+	w := NewJSON(&buf, Levels("\t"))
+	for i := range input {
+		if _, err := w.Write([]byte(input[i : i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("byte at a time got %q, want %q", got, want)
+	}
+}
+
+func TestNewJSONShortWrite(t *testing.T) {
+	input := []byte(`{"a":1}`)
+	// The output is "{\n  \"a\": 1\n}".  After 8 bytes of output ("{\n  \"a\":")
+	// we have consumed 5 bytes of input.
+	fw := &fakeWriter{left: 8}
+	n, err := NewJSON(fw, Levels("  ")).Write(input)
+	if err == nil {
+		t.Errorf("short write did not return an error")
+	}
+	if n != 5 {
+		t.Errorf("got %d, want 5", n)
+	}
+}
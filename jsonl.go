@@ -0,0 +1,112 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// A jsonLinesWriter prefixes, and optionally pretty prints, JSON Lines records.
+type jsonLinesWriter struct {
+	w       io.Writer
+	prefix  string
+	level   func(int) string // nil if not pretty printing
+	pending []byte           // a partial record
+	out     outbuf
+	rec     bytes.Buffer
+	err     error
+}
+
+// NewJSONLines returns a writer that prefixes each JSON Lines (NDJSON) record
+// written to it with prefix and writes the result to w.  If indent is not the
+// empty string then each record that is valid JSON is pretty printed across
+// multiple lines, each starting with prefix, using indent for each level of
+// nesting.  Records that are not valid JSON are prefixed but otherwise passed
+// through unchanged.  Blank lines are passed through.
+//
+// Records are buffered until their terminating newline is written.  Every
+// record remains complete and newline terminated so, as long as prefix only
+// contains JSON whitespace, the output can still be read record by record
+// with a json.Decoder.
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewJSONLines(w io.Writer, prefix, indent string) io.Writer {
+	jw := &jsonLinesWriter{
+		w:      w,
+		prefix: prefix,
+	}
+	if indent != "" {
+		levels := Levels(indent)
+		jw.level = func(depth int) string {
+			return prefix + levels(depth)
+		}
+	}
+	return jw
+}
+
+func (j *jsonLinesWriter) Write(buf []byte) (int, error) {
+	if j.err != nil {
+		return 0, j.err
+	}
+	held := len(j.pending)
+	for len(buf) > 0 {
+		x := bytes.IndexByte(buf, '\n')
+		if x < 0 {
+			j.pending = append(j.pending, buf...)
+			break
+		}
+		line := buf[:x]
+		if len(j.pending) > 0 {
+			j.pending = append(j.pending, line...)
+			line = j.pending
+		}
+		j.record(line)
+		j.pending = j.pending[:0]
+		buf = buf[x+1:]
+	}
+	// A partial record counts as written once everything before it has
+	// been written.
+	j.out.drop(len(j.pending))
+	n, err := j.out.writeTo(j.w)
+	j.err = err
+	if n -= held; n < 0 {
+		n = 0
+	}
+	return n, err
+}
+
+// record adds the output for the record in line, which does not include its
+// newline, to j.out.
+func (j *jsonLinesWriter) record(line []byte) {
+	rec := bytes.TrimSpace(line)
+	switch {
+	case len(rec) == 0:
+		j.out.addString("\n")
+	case j.level != nil && json.Valid(rec):
+		j.rec.Reset()
+		NewJSON(&j.rec, j.level).Write(rec)
+		j.out.addString(j.prefix)
+		j.out.add(j.rec.Bytes())
+		j.out.addString("\n")
+	default:
+		j.out.addString(j.prefix)
+		j.out.add(line)
+		j.out.addString("\n")
+	}
+	j.out.drop(len(line) + 1)
+}
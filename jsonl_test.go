@@ -0,0 +1,108 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestNewJSONLines(t *testing.T) {
+	for _, tt := range []struct {
+		indent string
+		in     []string
+		out    string
+	}{
+		{},
+		{
+			in:  []string{"{\"a\":1}\n", "[1,2]\n"},
+			out: "  {\"a\":1}\n  [1,2]\n",
+		}, {
+			in:  []string{"{\"a\"", ":1}\n[1", ",2]", "\n"},
+			out: "  {\"a\":1}\n  [1,2]\n",
+		}, {
+			in:  []string{"{\"a\":1}\n", "[1,2]"}, // the last record is incomplete
+			out: "  {\"a\":1}\n",
+		}, {
+			indent: "\t",
+			in:     []string{"{\"a\":[1,2]}\n", "\n", "not json\n", "3\r\n"},
+			out:    "  {\n  \t\"a\": [\n  \t\t1,\n  \t\t2\n  \t]\n  }\n\n  not json\n  3\n",
+		}, {
+			indent: "\t",
+			in:     []string{" not json \r\n", " [1,\n"},
+			out:    "   not json \r\n   [1,\n",
+		},
+	} {
+		var buf bytes.Buffer
+		w := NewJSONLines(&buf, "  ", tt.indent)
+		for _, s := range tt.in {
+			n, err := w.Write([]byte(s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(s) {
+				t.Errorf("Write(%q) returned %d", s, n)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestNewJSONLinesDecode(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{"a": 1.0, "b": []interface{}{"x", "y"}},
+		[]interface{}{1.0, 2.0},
+		"str",
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(NewJSONLines(&buf, "    ", "  "))
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dec := json.NewDecoder(&buf)
+	for i, want := range records {
+		var got interface{}
+		if err := dec.Decode(&got); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("record %d got %v, want %v", i, got, want)
+		}
+	}
+	var x interface{}
+	if err := dec.Decode(&x); err != io.EOF {
+		t.Errorf("got %v, want EOF", err)
+	}
+}
+
+func TestNewJSONLinesShortWrite(t *testing.T) {
+	fw := &fakeWriter{left: 5}
+	w := NewJSONLines(fw, "> ", "")
+	// "> 1\n" fits but "> 2\n" does not.
+	n, err := w.Write([]byte("1\n2\n"))
+	if err == nil {
+		t.Fatal("short write did not return an error")
+	}
+	if n != 2 {
+		t.Errorf("got %d, want 2", n)
+	}
+}
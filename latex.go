@@ -0,0 +1,149 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrLaTeXEnd is returned when the body of a verbatim LaTeX environment
+// contains the line that would end the environment.
+var ErrLaTeXEnd = errors.New("indent: verbatim text ends its environment")
+
+// latexVerbatim are the environments whose bodies are written verbatim.
+var latexVerbatim = map[string]bool{
+	"verbatim":   true,
+	"verbatim*":  true,
+	"Verbatim":   true,
+	"lstlisting": true,
+	"minted":     true,
+}
+
+// latexEscapes are the replacements for the characters that are special in
+// LaTeX text.
+var latexEscapes = [256]string{
+	'\\': `\textbackslash{}`,
+	'{':  `\{`,
+	'}':  `\}`,
+	'#':  `\#`,
+	'$':  `\$`,
+	'%':  `\%`,
+	'&':  `\&`,
+	'_':  `\_`,
+	'~':  `\textasciitilde{}`,
+	'^':  `\textasciicircum{}`,
+}
+
+// A latexWriter writes the body of a LaTeX environment.
+type latexWriter struct {
+	w        io.Writer
+	begin    []byte
+	end      []byte
+	indent   []byte
+	verbatim bool
+	out      outbuf
+	err      error
+	started  bool   // begin has been written
+	sol      bool   // we are at the start of a line
+	tail     []byte // the end of the verbatim text written so far
+}
+
+// NewLaTeX returns a writer that writes what is written to it to w as the
+// body of the LaTeX environment env.  The \begin line, followed by args, such
+// as "[language=Go]", is written before the body and the \end line is written
+// by Close, after which the writer must not be used.  Close does not close w.
+//
+// The bodies of the verbatim environments, verbatim, verbatim*, Verbatim,
+// lstlisting and minted, are written unchanged and unindented, as any change
+// would be displayed.  A body that contains the \end of its environment
+// returns ErrLaTeXEnd.  The bodies of other environments, such as quote, have
+// the characters special to LaTeX escaped and each line is started with
+// indent.
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewLaTeX(w io.Writer, env, args, indent string) io.WriteCloser {
+	return &latexWriter{
+		w:        w,
+		begin:    []byte(`\begin{` + env + "}" + args + "\n"),
+		end:      []byte(`\end{` + env + "}"),
+		indent:   []byte(indent),
+		verbatim: latexVerbatim[env],
+		sol:      true,
+	}
+}
+
+func (l *latexWriter) Write(buf []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	if !l.started {
+		l.out.add(l.begin)
+		l.started = true
+	}
+	if l.verbatim {
+		text := append(l.tail, buf...)
+		if bytes.Contains(text, l.end) {
+			l.err = ErrLaTeXEnd
+			return 0, l.err
+		}
+		if x := len(text) - len(l.end) + 1; x > 0 {
+			text = text[x:]
+		}
+		l.tail = append(l.tail[:0], text...)
+		l.out.copy(buf)
+	} else {
+		for _, c := range buf {
+			if l.sol && c != '\n' {
+				l.out.add(l.indent)
+			}
+			l.sol = c == '\n'
+			if e := latexEscapes[c]; e != "" {
+				l.out.addString(e)
+				l.out.drop(1)
+				continue
+			}
+			l.out.copyByte(c)
+		}
+	}
+	l.sol = buf[len(buf)-1] == '\n'
+	n, err := l.out.writeTo(l.w)
+	l.err = err
+	return n, err
+}
+
+// Close ends the environment, writing its \begin line first if nothing was
+// written to the body.
+func (l *latexWriter) Close() error {
+	if l.err != nil {
+		return l.err
+	}
+	if !l.started {
+		l.out.add(l.begin)
+		l.started = true
+	}
+	if !l.sol {
+		l.out.addString("\n")
+	}
+	l.out.add(l.end)
+	l.out.addString("\n")
+	_, l.err = l.out.writeTo(l.w)
+	return l.err
+}
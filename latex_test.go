@@ -0,0 +1,84 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestNewLaTeX(t *testing.T) {
+	for _, tt := range []struct {
+		env, args string
+		in        []string
+		out       string
+		err       error
+	}{
+		{
+			env: "quote",
+			in:  []string{"50% of $x_1$\n", "\n{a} & b\n"},
+			out: "\\begin{quote}\n  50\\% of \\$x\\_1\\$\n\n  \\{a\\} \\& b\n\\end{quote}\n",
+		},
+		{
+			env: "quote",
+			in:  []string{`a\b~c^d#`},
+			out: "\\begin{quote}\n  a\\textbackslash{}b\\textasciitilde{}c\\textasciicircum{}d\\#\n\\end{quote}\n",
+		},
+		{
+			env:  "lstlisting",
+			args: "[language=Go]",
+			in:   []string{"\tx := \"50%\"\n", "y := `\\n`\n"},
+			out:  "\\begin{lstlisting}[language=Go]\n\tx := \"50%\"\ny := `\\n`\n\\end{lstlisting}\n",
+		},
+		{
+			env: "verbatim",
+			out: "\\begin{verbatim}\n\\end{verbatim}\n",
+		},
+		{
+			env: "verbatim",
+			in:  []string{"a\n\\end{verb", "atim}\n"},
+			out: "\\begin{verbatim}\na\n\\end{verb",
+			err: ErrLaTeXEnd,
+		},
+	} {
+		var buf bytes.Buffer
+		w := NewLaTeX(&buf, tt.env, tt.args, "  ")
+		var err error
+		for _, s := range tt.in {
+			if _, err = io.WriteString(w, s); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		if err != tt.err {
+			t.Errorf("%s %q: got error %v, want %v", tt.env, tt.in, err, tt.err)
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%s %q: got:\n%s\nwant:\n%s", tt.env, tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestNewLaTeXShort(t *testing.T) {
+	// "\begin{quote}\n" is 14 bytes followed by the 4 bytes "  \%".
+	fw := &fakeWriter{left: 18}
+	w := NewLaTeX(fw, "quote", "", "  ")
+	if n, err := io.WriteString(w, "%%"); n != 1 || err != io.EOF {
+		t.Errorf("got %d, %v, want 1, %v", n, err, io.EOF)
+	}
+}
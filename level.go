@@ -0,0 +1,79 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrOutdent is returned by Outdent when the writer is not indented.
+var ErrOutdent = errors.New("indent: outdent below depth 0")
+
+// A LevelWriter is a writer whose depth of indentation is changed by calling
+// its Indent and Outdent methods rather than by creating nested writers.  Each
+// line written is indented by one copy of the unit of indentation for each
+// level of depth.  As with other writers returned by this package, the depth
+// should normally only be changed at the start of a line.  A LevelWriter is
+// not safe for concurrent use.
+type LevelWriter struct {
+	levels []*indenter // levels[d] is the writer for depth d
+	depth  int
+	unit   string
+}
+
+// NewLevelWriter returns a LevelWriter at depth 0 that writes to w and
+// indents by unit, such as "\t" or "  ", per level of depth.  If w was
+// returned by New then the prefix of w starts each line.
+func NewLevelWriter(w io.Writer, unit string) *LevelWriter {
+	return &LevelWriter{
+		levels: []*indenter{newIndenter(w, "")},
+		unit:   unit,
+	}
+}
+
+// Write writes buf indented to the current depth.
+func (lw *LevelWriter) Write(buf []byte) (int, error) {
+	return lw.levels[lw.depth].Write(buf)
+}
+
+// Indent increases the depth of the writer by one level.
+func (lw *LevelWriter) Indent() {
+	lw.depth++
+	if lw.depth == len(lw.levels) {
+		lw.levels = append(lw.levels, newIndenter(lw.levels[lw.depth-1], lw.unit))
+	}
+}
+
+// Outdent decreases the depth of the writer by one level.  It returns
+// ErrOutdent, leaving the depth at 0, if the depth is already 0.
+func (lw *LevelWriter) Outdent() error {
+	if lw.depth == 0 {
+		return ErrOutdent
+	}
+	lw.depth--
+	return nil
+}
+
+// Depth returns the current depth of the writer.
+func (lw *LevelWriter) Depth() int {
+	return lw.depth
+}
+
+// Flush flushes the writer, as the Flush method of a writer returned by New
+// does.
+func (lw *LevelWriter) Flush() error {
+	return lw.levels[0].Flush()
+}
@@ -0,0 +1,69 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestLevelWriter(t *testing.T) {
+	var buf bytes.Buffer
+	lw := NewLevelWriter(&buf, "  ")
+	io.WriteString(lw, "func f() {\n")
+	lw.Indent()
+	io.WriteString(lw, "if x {\n")
+	lw.Indent()
+	io.WriteString(lw, "return\n")
+	if d := lw.Depth(); d != 2 {
+		t.Errorf("got depth %d, want 2", d)
+	}
+	lw.Outdent()
+	io.WriteString(lw, "}\n")
+	lw.Indent()
+	io.WriteString(lw, "// partial ")
+	lw.Outdent()
+	io.WriteString(lw, "line\n")
+	lw.Outdent()
+	io.WriteString(lw, "}\n")
+	if err := lw.Outdent(); err != ErrOutdent {
+		t.Errorf("Outdent at depth 0 got %v, want %v", err, ErrOutdent)
+	}
+	if d := lw.Depth(); d != 0 {
+		t.Errorf("got depth %d, want 0", d)
+	}
+	want := `func f() {
+  if x {
+    return
+  }
+    // partial line
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLevelWriterNested(t *testing.T) {
+	var buf bytes.Buffer
+	lw := NewLevelWriter(New(&buf, "// "), "\t")
+	io.WriteString(lw, "a\n")
+	lw.Indent()
+	io.WriteString(lw, "b\n")
+	if got, want := buf.String(), "// a\n// \tb\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// ErrMaxDepth is returned by the writes to a writer nested deeper than the
+// limit set by WithMaxDepth with the Fail action.
+var ErrMaxDepth = errors.New("indent: maximum nesting depth exceeded")
+
+// ErrMaxPrefixLen is returned by the writes to a writer whose prefix is longer
+// than the limit set by WithMaxPrefixLen with the Fail action.
+var ErrMaxPrefixLen = errors.New("indent: maximum prefix length exceeded")
+
+// limitMarker ends a prefix that was truncated by WithMaxDepth or
+// WithMaxPrefixLen.
+const limitMarker = "…"
+
+// WithMaxDepth limits the nesting depth of the chain of writers to n.  It is
+// a guard rail against a recursive renderer with a bug nesting writers without
+// end.  A writer nested deeper than n is handled as specified by onExceed.
+// With Fail, all writes to the writer return ErrMaxDepth and write nothing.
+// Otherwise the writer uses the prefix of the writer at depth n followed by
+// "…".  The writer created by the first call to New has a depth of 1.  A
+// limit of 0 or less is no limit.
+func WithMaxDepth(n int, onExceed Action) Option {
+	return func(in *indenter) {
+		o := in.s.opts()
+		o.maxDepth = n
+		o.onDeep = onExceed
+		in.limitPrefix()
+	}
+}
+
+// WithMaxPrefixLen limits the prefixes of the chain of writers, including the
+// prefixes of the writers they are nested in, to n bytes.  A writer whose
+// prefix would be longer is handled as specified by onExceed.  With Fail, all
+// writes to the writer return ErrMaxPrefixLen and write nothing.  Otherwise the
+// prefix is truncated to n bytes, the last of which are "…" if there is room.
+// A truncated prefix always starts with the prefix of the writer it is nested
+// in, so if that prefix leaves no room for "…" the truncated prefix is that
+// prefix followed by "…".  A limit of 0 or less is no limit.
+func WithMaxPrefixLen(n int, onExceed Action) Option {
+	return func(in *indenter) {
+		o := in.s.opts()
+		o.maxPrefix = n
+		o.onLong = onExceed
+		in.limitPrefix()
+	}
+}
+
+// limitPrefix applies the limits of WithMaxDepth and WithMaxPrefixLen to in,
+// which was just created.
+func (in *indenter) limitPrefix() {
+	if in.s.opt == nil {
+		return
+	}
+	if n := in.s.opt.maxDepth; n > 0 && in.depth() > n {
+		if in.s.opt.onDeep == Fail {
+			in.opts().err = ErrMaxDepth
+			return
+		}
+		p := in
+		for p.depth() > n {
+			p = p.p
+		}
+		in.setPrefix(append(p.prefix[:len(p.prefix):len(p.prefix)], limitMarker...))
+	}
+	if n := in.s.opt.maxPrefix; n > 0 && len(in.prefix) > n {
+		if in.s.opt.onLong == Fail {
+			in.opts().err = ErrMaxPrefixLen
+			return
+		}
+		cut := n
+		if n > len(limitMarker) {
+			cut = n - len(limitMarker)
+		}
+		// The prefix of the writer we are nested in is kept whole.
+		keep := 0
+		if in.p != nil {
+			keep = len(in.p.prefix)
+		}
+		if cut < keep {
+			cut = keep
+		}
+		for cut > keep && !utf8.RuneStart(in.prefix[cut]) {
+			cut--
+		}
+		prefix := append([]byte(nil), in.prefix[:cut]...)
+		if n > len(limitMarker) {
+			prefix = append(prefix, limitMarker...)
+		}
+		in.setPrefix(prefix)
+	}
+}
@@ -0,0 +1,98 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestWithMaxDepth(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "1", WithMaxDepth(2, Truncate))
+	w2 := New(w, "2")
+	w3 := New(w2, "3")
+	w4 := New(w3, "4")
+	for _, w := range []io.Writer{w, w2, w3, w4} {
+		io.WriteString(w, "x\n")
+	}
+	if got, want := buf.String(), "1x\n12x\n12…x\n12…x\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	w = New(&buf, "1", WithMaxDepth(2, Fail))
+	w3 = New(New(w, "2"), "3")
+	if n, err := io.WriteString(w3, "x\n"); n != 0 || err != ErrMaxDepth {
+		t.Errorf("got %d, %v, want 0, %v", n, err, ErrMaxDepth)
+	}
+	if n, err := w3.(*indenter).WriteAll([]byte("x\n")); n != 0 || err != ErrMaxDepth {
+		t.Errorf("WriteAll got %d, %v, want 0, %v", n, err, ErrMaxDepth)
+	}
+	io.WriteString(w, "ok\n")
+	if got, want := buf.String(), "1ok\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithMaxPrefixLen(t *testing.T) {
+	for _, tt := range []struct {
+		max      int
+		prefixes []string
+		out      string
+	}{
+		{max: 8, prefixes: []string{"ab", "cd"}, out: "abcdx\n"},
+		{max: 8, prefixes: []string{"abcd", "efgh", "ijkl"}, out: "abcdefgh…x\n"},
+		{max: 8, prefixes: []string{"abcd", "€", "ijkl"}, out: "abcd€…x\n"},
+		{max: 7, prefixes: []string{"abc", "defgh"}, out: "abcd…x\n"},
+		{max: 8, prefixes: []string{"aaaa", "aaaa", "aaaa"}, out: "aaaaaaaa…x\n"},
+		{max: 2, prefixes: []string{"abcd"}, out: "abx\n"},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, tt.prefixes[0], WithMaxPrefixLen(tt.max, Truncate))
+		for _, p := range tt.prefixes[1:] {
+			w = New(w, p)
+		}
+		io.WriteString(w, "x\n")
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.prefixes, got, tt.out)
+		}
+	}
+
+	var buf bytes.Buffer
+	w := New(&buf, "abcd", WithMaxPrefixLen(6, Fail))
+	w2 := New(w, "efgh")
+	if n, err := io.WriteString(w2, "x\n"); n != 0 || err != ErrMaxPrefixLen {
+		t.Errorf("got %d, %v, want 0, %v", n, err, ErrMaxPrefixLen)
+	}
+}
+
+func TestWithMaxPrefixLenRune(t *testing.T) {
+	// Backing up to the start of a rune must not cut into the parent's
+	// prefix.
+	var buf bytes.Buffer
+	w := New(&buf, "ab", WithMaxPrefixLen(7, Truncate))
+	w2 := New(w, "ሴሴ")
+	io.WriteString(w2, "y\n")
+	if got, want := buf.String(), "ab…y\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	s := w2.(*indenter).Snapshot()
+	if got, want := fmt.Sprintf("%q", s.Prefixes), `["ab" "…"]`; got != want {
+		t.Errorf("got prefixes %s, want %s", got, want)
+	}
+}
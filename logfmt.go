@@ -0,0 +1,213 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A logfmtValue is the location of a value in a logfmt record.
+type logfmtValue struct {
+	key        string
+	start, end int // the value, including any quotes, is line[start:end]
+}
+
+// logfmtValues returns the values in the logfmt record line.
+func logfmtValues(line string) []logfmtValue {
+	var values []logfmtValue
+	for i := 0; i < len(line); {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+		k := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' {
+			i++
+		}
+		if i == len(line) || line[i] != '=' {
+			continue
+		}
+		key := line[k:i]
+		i++
+		start := i
+		if i == len(line) || line[i] != '"' {
+			for i < len(line) && line[i] != ' ' {
+				i++
+			}
+			values = append(values, logfmtValue{key: key, start: start, end: i})
+			continue
+		}
+		for i++; i < len(line) && line[i] != '"'; i++ {
+			if line[i] == '\\' {
+				i++
+			}
+		}
+		if i < len(line) {
+			i++
+		}
+		values = append(values, logfmtValue{key: key, start: start, end: i})
+	}
+	return values
+}
+
+// FoldLogfmt returns the logfmt records in text with the quoted values that
+// contain newlines folded onto continuation lines.  A folded value is
+// replaced by "|" in its record and its lines follow the record, each started
+// by prefix.  The first line of a value follows its key and a colon and the
+// rest are aligned with it.  For example, with a prefix of two spaces
+//
+//	level=error msg="panic" stack="goroutine 1:\nmain.main()"
+//
+// becomes
+//
+//	level=error msg="panic" stack=|
+//	  stack: goroutine 1:
+//	         main.main()
+//
+// Trailing spaces in a value are kept, and if text does not end in a newline
+// neither does the result.  UnfoldLogfmt reverses FoldLogfmt.
+func FoldLogfmt(text, prefix string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		nl := strings.HasSuffix(line, "\n")
+		line = strings.TrimSuffix(line, "\n")
+		var blocks [][2]string // the key and value of each folded value
+		last := 0
+		for _, v := range logfmtValues(line) {
+			if v.start == v.end || line[v.start] != '"' {
+				continue
+			}
+			value, err := strconv.Unquote(line[v.start:v.end])
+			if err != nil || !strings.Contains(value, "\n") {
+				continue
+			}
+			b.WriteString(line[last:v.start])
+			b.WriteString("|")
+			last = v.end
+			blocks = append(blocks, [2]string{v.key, value})
+		}
+		b.WriteString(line[last:])
+		if nl || len(blocks) > 0 {
+			b.WriteByte('\n')
+		}
+		for i, block := range blocks {
+			b.WriteString(foldValue(prefix, block[0], block[1], !nl && i == len(blocks)-1))
+		}
+	}
+	return b.String()
+}
+
+// foldValue returns the continuation lines of the value of key.  If last is
+// set the lines end the text, which did not end in a newline, so the final
+// line is not ended and, even when the value ends in a newline, keeps its
+// padding to show it is a line of the value.  Otherwise empty lines are
+// written without trailing spaces.
+func foldValue(prefix, key, value string, last bool) string {
+	var b strings.Builder
+	label := key + ": "
+	pad := strings.Repeat(" ", len(label))
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		end := last && i == len(lines)-1
+		if i == 0 {
+			line = prefix + label + line
+		} else {
+			line = prefix + pad + line
+		}
+		if !end && len(line) == len(prefix)+len(pad) {
+			line = strings.TrimRight(line, " ")
+		}
+		b.WriteString(line)
+		if !end {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// UnfoldLogfmt returns the logfmt records in text, as folded by FoldLogfmt
+// using prefix, with their folded values quoted in their records.  Lines that
+// do not continue a record are returned unchanged.
+func UnfoldLogfmt(text, prefix string) string {
+	var b strings.Builder
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		var folded []logfmtValue
+		for _, v := range logfmtValues(line) {
+			if line[v.start:v.end] == "|" {
+				folded = append(folded, v)
+			}
+		}
+		if len(folded) == 0 {
+			b.WriteString(line)
+			if i < len(lines)-1 {
+				b.WriteByte('\n')
+			}
+			continue
+		}
+		// The values are collected from the continuation lines that
+		// follow the record.
+		values := make([][]string, len(folded))
+		n := -1
+		for ; i+1 < len(lines); i++ {
+			if i+2 == len(lines) && lines[i+1] == "" {
+				// The end of text.
+				break
+			}
+			text, next := unfoldLine(lines[i+1], prefix, folded, n)
+			if next < 0 {
+				break
+			}
+			n = next
+			values[n] = append(values[n], text)
+		}
+		last := 0
+		for j, v := range folded {
+			b.WriteString(line[last:v.start])
+			b.WriteString(strconv.Quote(strings.Join(values[j], "\n")))
+			last = v.end
+		}
+		b.WriteString(line[last:])
+		if i < len(lines)-1 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// unfoldLine returns the text of line, a continuation line of the folded
+// value n, and the number of the value it continues.  The first line of a
+// value starts with its key.  The number is -1 if line is not a continuation.
+func unfoldLine(line, prefix string, folded []logfmtValue, n int) (string, int) {
+	if n >= 0 && isBlank(line) && !strings.HasPrefix(line, prefix+strings.Repeat(" ", len(folded[n].key)+2)) {
+		return "", n
+	}
+	if !strings.HasPrefix(line, prefix) {
+		return "", -1
+	}
+	text := line[len(prefix):]
+	if n+1 < len(folded) && strings.HasPrefix(text, folded[n+1].key+":") {
+		n++
+		return strings.TrimPrefix(text[len(folded[n].key)+1:], " "), n
+	}
+	if n >= 0 {
+		if pad := strings.Repeat(" ", len(folded[n].key)+2); strings.HasPrefix(text, pad) {
+			return text[len(pad):], n
+		}
+	}
+	return "", -1
+}
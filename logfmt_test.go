@@ -0,0 +1,77 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "testing"
+
+func TestFoldLogfmt(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		record, fold string
+	}{
+		{
+			name:   "plain",
+			record: "level=info msg=\"hello world\" n=1\n",
+			fold:   "level=info msg=\"hello world\" n=1\n",
+		},
+		{
+			name:   "folded",
+			record: `level=error msg="panic" stack="goroutine 1:\nmain.main()"` + "\n",
+			fold: `level=error msg="panic" stack=|
+  stack: goroutine 1:
+         main.main()
+`,
+		},
+		{
+			name:   "two values",
+			record: `a="x\ny" b=2 c="\n  z\n"` + "\nnext=1\n",
+			fold: `a=| b=2 c=|
+  a: x
+     y
+  c:
+       z
+
+next=1
+`,
+		},
+		{
+			name:   "trailing spaces",
+			record: `s="x \n  \ny"` + "\n",
+			fold:   "s=|\n  s: x \n       \n     y\n",
+		},
+		{
+			name:   "no final newline",
+			record: `a=1 s="x\ny"`,
+			fold:   "a=1 s=|\n  s: x\n     y",
+		},
+		{
+			name:   "no final newline after a newline",
+			record: `s="x\n"`,
+			fold:   "s=|\n  s: x\n     ",
+		},
+		{
+			name:   "bare escapes are not folded",
+			record: `msg=a\nb bad="\q` + "\n",
+			fold:   `msg=a\nb bad="\q` + "\n",
+		},
+	} {
+		if got := FoldLogfmt(tt.record, "  "); got != tt.fold {
+			t.Errorf("%s: FoldLogfmt got:\n%s\nwant:\n%s", tt.name, got, tt.fold)
+		}
+		if got := UnfoldLogfmt(tt.fold, "  "); got != tt.record {
+			t.Errorf("%s: UnfoldLogfmt got:\n%s\nwant:\n%s", tt.name, got, tt.record)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+)
+
+// A tabWriter prefixes lines while keeping leading tabs first.
+type tabWriter struct {
+	w      io.Writer
+	prefix []byte
+	out    outbuf
+	err    error
+	sol    bool
+}
+
+// NewMakefile returns a writer that prefixes each line written to it with
+// prefix and writes the results to w.  Lines that start with a tab, such as
+// recipe lines in a Makefile, have the prefix inserted after the tab so the
+// line still starts with the tab that make requires.  Tabs are never converted
+// to spaces.  NewMakefile returns w if prefix is the empty string.
+//
+//	all: prog
+//		go build
+//
+// indented with "  " becomes
+//
+//	  all: prog
+//		  go build
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewMakefile(w io.Writer, prefix string) io.Writer {
+	if len(prefix) == 0 {
+		return w
+	}
+	return &tabWriter{
+		w:      w,
+		prefix: []byte(prefix),
+		sol:    true,
+	}
+}
+
+func (t *tabWriter) Write(buf []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	for len(buf) > 0 {
+		if t.sol {
+			t.sol = false
+			if buf[0] == '\t' {
+				t.out.copyByte('\t')
+				buf = buf[1:]
+			}
+			t.out.add(t.prefix)
+			continue
+		}
+		x := bytes.IndexByte(buf, '\n')
+		if x < 0 {
+			t.out.copy(buf)
+			break
+		}
+		t.out.copy(buf[:x+1])
+		t.sol = true
+		buf = buf[x+1:]
+	}
+	n, err := t.out.writeTo(t.w)
+	t.err = err
+	return n, err
+}
@@ -0,0 +1,50 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewMakefile(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{},
+		{
+			in:  []string{"all: prog\n\tgo build\n"},
+			out: "  all: prog\n\t  go build\n",
+		}, {
+			in:  []string{"all:", " prog\n", "\t", "go build\n\n", "x = 1"},
+			out: "  all: prog\n\t  go build\n  \n  x = 1",
+		}, {
+			in:  []string{"a:\n\t\techo \\\n\t\t  hi\n"},
+			out: "  a:\n\t  \techo \\\n\t  \t  hi\n",
+		},
+	} {
+		var buf bytes.Buffer
+		w := NewMakefile(&buf, "  ")
+		for _, s := range tt.in {
+			if _, err := w.Write([]byte(s)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "strings"
+
+// TP returns tag and text laid out as the roff .TP macro lays out a tagged
+// paragraph in plain text, such as in an option list:
+//
+//	-v, --verbose
+//	        Write more about what is being done. Each
+//	        use increases the verbosity.
+//	-q      Write less.
+//
+// The text is wrapped so its lines start at column and are no wider than
+// width columns.  If tag fits before column, with room for at least one
+// space, the text starts on the same line as tag, otherwise it starts on the
+// next line.  Paragraphs in text are separated by blank lines and are
+// separated by a blank line in the result.  Newlines within a paragraph are
+// not significant.  Each line of the result ends in a newline.  If width is
+// not positive the text is not wrapped.
+func TP(tag, text string, column, width int) string {
+	var paras []string
+	var para []string
+	for _, line := range strings.Split(text, "\n") {
+		if !isBlank(line) {
+			para = append(para, line)
+			continue
+		}
+		if len(para) > 0 {
+			paras = append(paras, strings.Join(para, " "))
+			para = nil
+		}
+	}
+	if len(para) > 0 {
+		paras = append(paras, strings.Join(para, " "))
+	}
+
+	rest := strings.Repeat(" ", column)
+	if len(paras) == 0 {
+		return tag + "\n"
+	}
+	var b strings.Builder
+	first := rest
+	if tw := displayWidth(tag); tw < column {
+		first = tag + strings.Repeat(" ", column-tw)
+	} else {
+		b.WriteString(tag)
+		b.WriteByte('\n')
+	}
+	b.WriteString(Hanging(first, rest, strings.Join(paras, "\n\n"), width))
+	return b.String()
+}
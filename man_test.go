@@ -0,0 +1,68 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "testing"
+
+func TestTP(t *testing.T) {
+	for _, tt := range []struct {
+		tag, text string
+		column    int
+		width     int
+		out       string
+	}{
+		{
+			tag:    "-q",
+			text:   "Write less.",
+			column: 8, width: 40,
+			out: "-q      Write less.\n",
+		},
+		{
+			tag:    "-v, --verbose",
+			text:   "Write more about what is being done.  Each\nuse increases the verbosity.",
+			column: 8, width: 50,
+			out: "-v, --verbose\n" +
+				"        Write more about what is being done. Each\n" +
+				"        use increases the verbosity.\n",
+		},
+		{
+			tag:    "-abcdef",
+			text:   "Exactly fits.",
+			column: 8, width: 40,
+			out: "-abcdef Exactly fits.\n",
+		},
+		{
+			tag:    "-abcdefg",
+			text:   "Too long.",
+			column: 8, width: 40,
+			out: "-abcdefg\n        Too long.\n",
+		},
+		{
+			tag:    "-n",
+			text:   "\nFirst paragraph.\n\n\nSecond\nparagraph.\n",
+			column: 4, width: 0,
+			out: "-n  First paragraph.\n\n    Second paragraph.\n",
+		},
+		{
+			tag:    "-x",
+			column: 4, width: 40,
+			out: "-x\n",
+		},
+	} {
+		if got := TP(tt.tag, tt.text, tt.column, tt.width); got != tt.out {
+			t.Errorf("TP(%q) got:\n%s\nwant:\n%s", tt.tag, got, tt.out)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "strings"
+
+// A CodeBlock is a code block extracted from a Markdown document.
+type CodeBlock struct {
+	Lang   string // the first word of the info string of a fenced block
+	Info   string // the full info string of a fenced block
+	Code   string // the dedented contents of the block
+	Line   int    // the line number of the first line of Code (1 based)
+	Fenced bool   // true for fenced blocks, false for indented blocks
+}
+
+// A fence is the opening line of a fenced code block.
+type fence struct {
+	c      byte   // '`' or '~'
+	n      int    // number of fence characters
+	indent int    // indentation of the fence
+	info   string // the info string
+}
+
+// openFence returns the fence opened by line, if any.
+func openFence(line string) (fence, bool) {
+	var f fence
+	for f.indent < len(line) && f.indent < 4 && line[f.indent] == ' ' {
+		f.indent++
+	}
+	if f.indent > 3 || f.indent == len(line) {
+		return f, false
+	}
+	rest := line[f.indent:]
+	f.c = rest[0]
+	if f.c != '`' && f.c != '~' {
+		return f, false
+	}
+	for f.n < len(rest) && rest[f.n] == f.c {
+		f.n++
+	}
+	if f.n < 3 {
+		return f, false
+	}
+	f.info = strings.TrimSpace(rest[f.n:])
+	if f.c == '`' && strings.IndexByte(f.info, '`') >= 0 {
+		return f, false
+	}
+	return f, true
+}
+
+// closes returns true if line closes f.
+func (f fence) closes(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return false
+	}
+	n := len(trimmed) - len(strings.TrimLeft(trimmed, string(f.c)))
+	return n >= f.n && strings.TrimSpace(trimmed[n:]) == ""
+}
+
+// isListItem returns true if line starts a Markdown list item.
+func isListItem(line string) bool {
+	line = strings.TrimLeft(line, " ")
+	if len(line) >= 2 && strings.IndexByte("-*+", line[0]) >= 0 && (line[1] == ' ' || line[1] == '\t') {
+		return true
+	}
+	n := 0
+	for n < len(line) && line[n] >= '0' && line[n] <= '9' {
+		n++
+	}
+	return n > 0 && n+1 < len(line) && (line[n] == '.' || line[n] == ')') && (line[n+1] == ' ' || line[n+1] == '\t')
+}
+
+// CodeBlocks returns the fenced and indented code blocks found in markdown,
+// in the order they appear.  The contents of each block are dedented: an
+// indented block first has its four spaces (or tab) of indentation removed,
+// a fenced block has the indentation of its fence removed, and then the
+// longest common leading whitespace is removed from all lines.  Indented
+// lines that are part of a paragraph or a list item are not code.
+func CodeBlocks(markdown string) []CodeBlock {
+	var blocks []CodeBlock
+	lines := strings.SplitAfter(markdown, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	para := false // the previous line was part of a paragraph
+	list := false // we are in a list
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		if f, ok := openFence(line); ok {
+			block := CodeBlock{
+				Info:   f.info,
+				Line:   i + 2,
+				Fenced: true,
+			}
+			if x := strings.IndexAny(f.info, " \t"); x >= 0 {
+				block.Lang = f.info[:x]
+			} else {
+				block.Lang = f.info
+			}
+			var code []string
+			for i++; i < len(lines); i++ {
+				line := strings.TrimRight(lines[i], "\r\n")
+				if f.closes(line) {
+					break
+				}
+				n := 0
+				for n < f.indent && n < len(line) && line[n] == ' ' {
+					n++
+				}
+				code = append(code, line[n:])
+			}
+			block.Code = codeString(code)
+			blocks = append(blocks, block)
+			para = false
+			continue
+		}
+
+		switch {
+		case isBlank(line):
+			para = false
+		case !para && !list && (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")):
+			block := CodeBlock{Line: i + 1}
+			var code []string
+			for ; i < len(lines); i++ {
+				line := strings.TrimRight(lines[i], "\r\n")
+				switch {
+				case strings.HasPrefix(line, "\t"):
+					line = line[1:]
+				case strings.HasPrefix(line, "    "):
+					line = line[4:]
+				case isBlank(line):
+					line = ""
+				default:
+					i--
+					goto done
+				}
+				code = append(code, line)
+			}
+		done:
+			// Trailing blank lines are not part of the block.
+			for len(code) > 0 && code[len(code)-1] == "" {
+				code = code[:len(code)-1]
+			}
+			block.Code = codeString(code)
+			blocks = append(blocks, block)
+		case isListItem(line):
+			list = true
+			para = true
+		default:
+			if line[0] != ' ' && line[0] != '\t' {
+				list = false
+			}
+			para = true
+		}
+	}
+	return blocks
+}
+
+// codeString returns the dedented lines as a single string.
+func codeString(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return Dedent(strings.Join(lines, "\n") + "\n")
+}
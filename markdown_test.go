@@ -0,0 +1,105 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodeBlocks(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		in     string
+		blocks []CodeBlock
+	}{
+		{name: "empty"},
+		{
+			name: "fenced",
+			in: "# Title\n" +
+				"\n" +
+				"```go run\n" +
+				"\tfmt.Println(\"hi\")\n" +
+				"\t\treturn\n" +
+				"```\n" +
+				"text\n",
+			blocks: []CodeBlock{{
+				Lang:   "go",
+				Info:   "go run",
+				Code:   "fmt.Println(\"hi\")\n\treturn\n",
+				Line:   4,
+				Fenced: true,
+			}},
+		}, {
+			name: "indented fence",
+			in: "  ~~~~\n" +
+				"  a\n" +
+				"    b\n" +
+				" c\n" +
+				"  ~~~\n" +
+				"  ~~~~\n",
+			blocks: []CodeBlock{{
+				Code:   "a\n  b\nc\n~~~\n",
+				Line:   2,
+				Fenced: true,
+			}},
+		}, {
+			name: "unclosed",
+			in:   "```sh\necho\n",
+			blocks: []CodeBlock{{
+				Lang:   "sh",
+				Info:   "sh",
+				Code:   "echo\n",
+				Line:   2,
+				Fenced: true,
+			}},
+		}, {
+			name: "indented",
+			in: "Some text\n" +
+				"\n" +
+				"    line 1\n" +
+				"\n" +
+				"      line 2\n" +
+				"\n" +
+				"\n" +
+				"more text\n" +
+				"    not code\n",
+			blocks: []CodeBlock{{
+				Code: "line 1\n\n  line 2\n",
+				Line: 3,
+			}},
+		}, {
+			name: "list",
+			in: "- item\n" +
+				"\n" +
+				"    continued item\n" +
+				"\n" +
+				"done\n" +
+				"\n" +
+				"\tcode\n",
+			blocks: []CodeBlock{{
+				Code: "code\n",
+				Line: 7,
+			}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := CodeBlocks(tt.in)
+			if !reflect.DeepEqual(blocks, tt.blocks) {
+				t.Errorf("got %+v, want %+v", blocks, tt.blocks)
+			}
+		})
+	}
+}
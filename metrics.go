@@ -0,0 +1,72 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "sync/atomic"
+
+// Metrics are package wide counters of the work done by String, Bytes, and the
+// writers returned by New.  They are intended to help attribute costs to the
+// indentation layer of a pipeline.  Metrics are only collected while enabled by
+// EnableMetrics.
+type Metrics struct {
+	Allocs        int64 // output buffers allocated
+	AllocsAvoided int64 // output buffers reused, or input returned unchanged
+	BytesCopied   int64 // bytes placed in output buffers
+	Writes        int64 // writes issued to underlying writers
+}
+
+var (
+	metricsOn     int32
+	allocs        int64
+	allocsAvoided int64
+	bytesCopied   int64
+	writes        int64
+)
+
+// EnableMetrics turns the collection of Metrics on or off.  When off, which is
+// the default, the cost of metrics is a single atomic load at each point a
+// metric would be counted.
+func EnableMetrics(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&metricsOn, v)
+}
+
+// ReadMetrics returns the current value of the metrics.
+func ReadMetrics() Metrics {
+	return Metrics{
+		Allocs:        atomic.LoadInt64(&allocs),
+		AllocsAvoided: atomic.LoadInt64(&allocsAvoided),
+		BytesCopied:   atomic.LoadInt64(&bytesCopied),
+		Writes:        atomic.LoadInt64(&writes),
+	}
+}
+
+// ResetMetrics sets all the metrics back to 0.
+func ResetMetrics() {
+	atomic.StoreInt64(&allocs, 0)
+	atomic.StoreInt64(&allocsAvoided, 0)
+	atomic.StoreInt64(&bytesCopied, 0)
+	atomic.StoreInt64(&writes, 0)
+}
+
+// count adds n to the metric c if metrics are enabled.
+func count(c *int64, n int64) {
+	if atomic.LoadInt32(&metricsOn) != 0 {
+		atomic.AddInt64(c, n)
+	}
+}
@@ -0,0 +1,58 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	defer EnableMetrics(false)
+	defer ResetMetrics()
+
+	run := func() {
+		Bytes([]byte("> "), []byte("a\nb\n"))
+		Bytes([]byte("> "), nil)
+		var buf bytes.Buffer
+		w := New(&buf, "> ", WithReusedBuffer())
+		w.Write([]byte("a\n"))
+		w.Write([]byte("b\n"))
+	}
+
+	EnableMetrics(false)
+	ResetMetrics()
+	run()
+	if got := ReadMetrics(); got != (Metrics{}) {
+		t.Errorf("disabled: got %+v, want %+v", got, Metrics{})
+	}
+
+	EnableMetrics(true)
+	run()
+	want := Metrics{
+		Allocs:        2,
+		AllocsAvoided: 2,
+		BytesCopied:   16,
+		Writes:        2,
+	}
+	if got := ReadMetrics(); got != want {
+		t.Errorf("enabled: got %+v, want %+v", got, want)
+	}
+
+	ResetMetrics()
+	if got := ReadMetrics(); got != (Metrics{}) {
+		t.Errorf("reset: got %+v, want %+v", got, Metrics{})
+	}
+}
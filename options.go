@@ -0,0 +1,124 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bufio"
+	"errors"
+)
+
+// ErrMaxOutput is returned by a writer that would exceed the limit set by
+// WithMaxOutput.
+var ErrMaxOutput = errors.New("indent: maximum output size exceeded")
+
+// An Option configures a writer returned by New.
+type Option func(*indenter)
+
+// WithReusedBuffer causes the chain of writers to keep a single output buffer
+// that is reused by every Write rather than allocating a new buffer for each
+// Write.  This is most useful for long lived writers, such as those used by
+// servers.  The trade-off is memory: the buffer grows to the size of the
+// largest indented Write and is retained until the writer's Reset method is
+// called.
+func WithReusedBuffer() Option {
+	return func(in *indenter) {
+		in.s.reuse = true
+		in.s.opts()
+	}
+}
+
+// WithBuffering causes the chain of writers to coalesce their output in a
+// buffer of size bytes before writing it to the underlying writer.  This is
+// important when many small writes are made and the underlying writer is an
+// os.File or a net.Conn.  The writer's Flush or Close method must be called
+// to write any buffered output.  Flush before writing to the underlying writer
+// directly, such as one returned by Unwrap.  WithBuffering has no effect if the
+// chain is already buffered.
+func WithBuffering(size int) Option {
+	return func(in *indenter) {
+		if o := in.s.opts(); o.bw == nil {
+			o.bw = bufio.NewWriterSize(in.s.w, size)
+		}
+	}
+}
+
+// WithMaxOutput limits the chain of writers to writing a total of n bytes of
+// indented output.  A Write that would exceed the limit writes as much of its
+// output as fits and then returns ErrMaxOutput, as do all subsequent writes.
+// This bounds the memory or disk used when embedding untrusted input in
+// indented output.
+func WithMaxOutput(n int64) Option {
+	return func(in *indenter) {
+		in.s.opts().max = n
+	}
+}
+
+// WithGutter pads the prefix each writer in the chain adds with spaces so it
+// is at least width columns wide, as measured on a terminal.  This makes each
+// level of nesting start at a predictable column even when the prefixes of the
+// levels differ, such as when they are labels.  For example, with a gutter of
+// 4, writers nested with the prefixes "a:", "bb:", and "|" indent their
+// content by 4, 8, and 12 columns.  WithGutter applies to the writer being
+// created and all the writers later nested within it.
+func WithGutter(width int) Option {
+	return func(in *indenter) {
+		in.s.opts().gutter = width
+		in.padPrefix()
+	}
+}
+
+// WithTabStop causes tabs in the prefixes of the chain of writers to be
+// expanded to spaces with tab stops every n columns.  Prefixes containing tabs
+// otherwise make it hard to know the column the text written after them
+// starts in.  WithTabStop applies to the writer being created and all the
+// writers later nested within it.  A tab stop that is not positive is 8.
+func WithTabStop(n int) Option {
+	if n <= 0 {
+		n = tabWidth
+	}
+	return func(in *indenter) {
+		in.s.opts().tabStop = n
+		in.expandPrefix()
+	}
+}
+
+// WithSuffix causes suffix to be written at the end of each line, just before
+// the newline, such as " \\" to continue a shell command or " |" to close a
+// table border.  Text that does not end in a newline is not yet the end of a
+// line, so the suffix is only written when the newline is.  WithSuffix applies
+// to the writer being created and the writers later nested within it.
+func WithSuffix(suffix string) Option {
+	return func(in *indenter) {
+		in.opts().postfix = []byte(suffix)
+	}
+}
+
+// WithFinalNewline causes the Close method of the writer being created to
+// write a newline if the text written ends part way through a line.  This
+// keeps a block of output that does not end in a newline from running into
+// what is written after it.
+func WithFinalNewline() Option {
+	return func(in *indenter) {
+		in.opts().finalNewline = true
+	}
+}
+
+// WithCloseOutput causes the Close method of the outermost writer of the chain
+// to close the underlying writer, if it is an io.Closer, after flushing it.
+func WithCloseOutput() Option {
+	return func(in *indenter) {
+		in.s.closeOutput = true
+	}
+}
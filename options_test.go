@@ -0,0 +1,350 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestWithReusedBuffer(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "> ", WithReusedBuffer())
+	in := w.(*indenter)
+	w.Write([]byte("line 1\nline 2\n"))
+	if cap(in.s.opt.buf) == 0 {
+		t.Fatal("buffer was not retained")
+	}
+	c := cap(in.s.opt.buf)
+	w2 := New(w, "..")
+	w2.Write([]byte("a\n"))
+	if cap(in.s.opt.buf) != c {
+		t.Errorf("buffer capacity changed from %d to %d", c, cap(in.s.opt.buf))
+	}
+	w.Write([]byte("line 3"))
+	if got, want := buf.String(), "> line 1\n> line 2\n> ..a\n> line 3"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	in.Reset()
+	if in.s.opt.buf != nil {
+		t.Errorf("Reset did not release the buffer")
+	}
+
+	input := []byte("some text\nto indent\n")
+	w = New(ioutil.Discard, "\t", WithReusedBuffer())
+	w.Write(input)
+	if n := testing.AllocsPerRun(100, func() { w.Write(input) }); n != 0 {
+		t.Errorf("got %v allocations per Write, want 0", n)
+	}
+}
+
+func TestReset(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "> ", WithMaxLines(2), WithReusedBuffer())
+	in := w.(*indenter)
+	io.WriteString(w, "a\nb\nc\nd")
+	in.Reset()
+	io.WriteString(w, "e\nf\n")
+	if got, want := buf.String(), "> a\n> b\n> e\n> f\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Output held by WithBuffering is discarded.
+	buf.Reset()
+	w = New(&buf, "> ", WithBuffering(64))
+	io.WriteString(w, "abc")
+	w.(*indenter).Reset()
+	io.WriteString(w, "x\n")
+	w.(*indenter).Flush()
+	if got, want := buf.String(), "> x\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A writer that exceeds a limit still fails.
+	w = New(New(&buf, "> ", WithMaxDepth(1, Fail)), "..")
+	w.(*indenter).Reset()
+	if _, err := io.WriteString(w, "x\n"); err != ErrMaxDepth {
+		t.Errorf("got error %v, want %v", err, ErrMaxDepth)
+	}
+
+	// ResetOutput keeps the buffer for the new writer.
+	var buf2 bytes.Buffer
+	w = New(&buf, "> ", WithReusedBuffer())
+	in = w.(*indenter)
+	io.WriteString(w, "a long line\nand more")
+	c := cap(in.s.opt.buf)
+	if c == 0 {
+		t.Fatal("buffer was not retained")
+	}
+	in.ResetOutput(&buf2)
+	io.WriteString(w, "h\n")
+	if got, want := buf2.String(), "> h\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if cap(in.s.opt.buf) != c {
+		t.Errorf("buffer capacity changed from %d to %d", c, cap(in.s.opt.buf))
+	}
+}
+
+func TestNewEmptyPrefixWithOptions(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "", WithReusedBuffer())
+	if _, ok := w.(*indenter); !ok {
+		t.Fatalf("New with options returned %T", w)
+	}
+	w.Write([]byte("a\nb\n"))
+	if got, want := buf.String(), "a\nb\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewAllocs(t *testing.T) {
+	// Options must not add to the cost of a writer without them: the
+	// indenter, its state, and its prefix.
+	if n := testing.AllocsPerRun(100, func() { New(ioutil.Discard, "> ") }); n != 3 {
+		t.Errorf("got %v allocations per New, want 3", n)
+	}
+
+	// Nor to its size, as the rarely used options are kept apart.  A plain
+	// writer was 96 bytes before the options; the counts kept for Stats
+	// account for the rest.
+	if testing.Short() {
+		return
+	}
+	if n := testing.Benchmark(BenchmarkNew).AllocedBytesPerOp(); n > 128 {
+		t.Errorf("got %d bytes per New, want at most 128", n)
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New(ioutil.Discard, "> ")
+	}
+}
+
+func BenchmarkNewWithOptions(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New(ioutil.Discard, "> ", WithDropCR())
+	}
+}
+
+func TestWithBuffering(t *testing.T) {
+	var buf bytes.Buffer
+	fw := &countingWriter{w: &buf}
+	w := New(fw, "> ", WithBuffering(64))
+	w2 := New(w, "..")
+	for i := 0; i < 4; i++ {
+		w.Write([]byte("a\n"))
+		w2.Write([]byte("b\n"))
+	}
+	if fw.writes != 0 {
+		t.Errorf("got %d writes before Flush, want 0", fw.writes)
+	}
+	if err := w2.(*indenter).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if fw.writes != 1 {
+		t.Errorf("got %d writes after Flush, want 1", fw.writes)
+	}
+	w.Write([]byte("c"))
+	if err := w.(io.Closer).Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Repeat("> a\n> ..b\n", 4) + "> c"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Without WithBuffering Flush does nothing.
+	if err := New(&buf, "x").(*indenter).Flush(); err != nil {
+		t.Errorf("Flush returned %v", err)
+	}
+}
+
+func TestWithMaxOutput(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "> ", WithMaxOutput(12))
+	if n, err := w.Write([]byte("abc\n")); n != 4 || err != nil {
+		t.Errorf("first write got %d, %v, want 4, nil", n, err)
+	}
+	// 6 more bytes of output will fit: "> def\n> gh" is cut to "> def\n".
+	n, err := w.Write([]byte("def\ngh\n"))
+	if err != ErrMaxOutput {
+		t.Errorf("got error %v, want %v", err, ErrMaxOutput)
+	}
+	if n != 4 {
+		t.Errorf("got %d, want 4", n)
+	}
+	if n, err := New(w, "..").Write([]byte("x")); n != 0 || err != ErrMaxOutput {
+		t.Errorf("nested write got %d, %v, want 0, %v", n, err, ErrMaxOutput)
+	}
+	if got, want := buf.String(), "> abc\n> def\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithGutter(t *testing.T) {
+	var buf bytes.Buffer
+	w1 := New(&buf, "a:", WithGutter(4))
+	w2 := New(w1, "bb:")
+	w3 := New(w2, "|")
+	w4 := New(w3, "toolong:")
+	w5 := New(w4, "日本")
+	for _, w := range []io.Writer{w1, w2, w3, w4, w5} {
+		io.WriteString(w, "x\n")
+	}
+	want := "" +
+		"a:  x\n" +
+		"a:  bb: x\n" +
+		"a:  bb: |   x\n" +
+		"a:  bb: |   toolong:x\n" +
+		"a:  bb: |   toolong:日本x\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWithTabStop(t *testing.T) {
+	var buf bytes.Buffer
+	w1 := New(&buf, "\t", WithTabStop(4))
+	w2 := New(w1, "ab\t")
+	w3 := New(w2, "日\t")
+	for _, w := range []io.Writer{w1, w2, w3} {
+		io.WriteString(w, "x\n")
+	}
+	want := "" +
+		"    x\n" +
+		"    ab  x\n" +
+		"    ab  日  x\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	io.WriteString(New(&buf, "a\tb", WithTabStop(0)), "x\n")
+	if got, want := buf.String(), "a       bx\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "| ", WithSuffix(" |"))
+	io.WriteString(w, "a\nb")
+	io.WriteString(w, "c\n")
+	io.WriteString(New(w, "> "), "d\n\n")
+	if got, want := buf.String(), "| a |\n| bc |\n| > d |\n| >  |\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Short writes account for the suffix.
+	for _, tt := range []struct {
+		max int
+		n   int
+		sol bool
+	}{
+		{max: 2, n: 0, sol: true},
+		{max: 4, n: 2},
+		{max: 6, n: 2},
+		{max: 7, n: 3, sol: true},
+		{max: 9, n: 3, sol: true},
+		{max: 10, n: 4},
+		{max: 14, n: 6, sol: true},
+	} {
+		fw := &fakeWriter{left: tt.max}
+		w := New(fw, "> ", WithSuffix(" |"))
+		n, _ := io.WriteString(w, "ab\ncd\n")
+		if n != tt.n {
+			t.Errorf("max %d: got %d, want %d", tt.max, n, tt.n)
+		}
+		if sol := w.(*indenter).s.sol; sol != tt.sol {
+			t.Errorf("max %d: got sol %v, want %v", tt.max, sol, tt.sol)
+		}
+	}
+}
+
+// closeWriter records calls to Close.
+type closeWriter struct {
+	bytes.Buffer
+	closed int
+}
+
+func (c *closeWriter) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestWithFinalNewline(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		opts []Option
+		out  string
+	}{
+		{in: "a\nb", out: "> a\n> b"},
+		{in: "a\nb", opts: []Option{WithFinalNewline()}, out: "> a\n> b\n"},
+		{in: "a\n", opts: []Option{WithFinalNewline()}, out: "> a\n"},
+		{in: "", opts: []Option{WithFinalNewline()}, out: ""},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", tt.opts...)
+		io.WriteString(w, tt.in)
+		if err := w.(io.Closer).Close(); err != nil {
+			t.Errorf("%q: Close: %v", tt.in, err)
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+
+	// A nested writer ends its line when closed.
+	var buf bytes.Buffer
+	w := New(&buf, "> ")
+	w2 := New(w, "  ", WithFinalNewline())
+	io.WriteString(w2, "a")
+	w2.(io.Closer).Close()
+	io.WriteString(w, "b\n")
+	if got, want := buf.String(), ">   a\n> b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithCloseOutput(t *testing.T) {
+	cw := &closeWriter{}
+	w := New(cw, "> ")
+	w.(io.Closer).Close()
+	if cw.closed != 0 {
+		t.Errorf("underlying writer closed without WithCloseOutput")
+	}
+
+	w = New(cw, "> ", WithCloseOutput(), WithBuffering(64))
+	w2 := New(w, "  ")
+	io.WriteString(w2, "a\n")
+	w2.(io.Closer).Close()
+	if cw.closed != 0 {
+		t.Errorf("underlying writer closed by a nested writer")
+	}
+	w.(io.Closer).Close()
+	if cw.closed != 1 {
+		t.Errorf("underlying writer closed %d times, want 1", cw.closed)
+	}
+	if got, want := cw.String(), ">   a\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "strings"
+
+// OrgOutline returns the indented outline text as org-mode headings.  Each
+// non-blank line becomes a heading whose depth, the number of stars, is one
+// more than the number of less indented lines it is nested under.  A line
+// indented less than the line before it is a sibling of the closest line
+// above it with no more indentation.  Blank lines are preserved.  For
+// example,
+//
+//	OrgOutline(`Fruit
+//	  Apple
+//	    Gala
+//	  Pear
+//	Vegetables
+//	`)
+//
+// returns
+//
+//	`* Fruit
+//	** Apple
+//	*** Gala
+//	** Pear
+//	* Vegetables
+//	`
+func OrgOutline(text string) string {
+	var b strings.Builder
+	var stack []string
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if isBlank(line) {
+			b.WriteByte('\n')
+			continue
+		}
+		ws := leading(line)
+		for len(stack) > 0 && len(ws) <= len(stack[len(stack)-1]) {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, ws)
+		b.WriteString(strings.Repeat("*", len(stack)))
+		b.WriteByte(' ')
+		b.WriteString(strings.TrimRight(line[len(ws):], " \t\r"))
+		b.WriteByte('\n')
+	}
+	if b.Len() == 1 {
+		return ""
+	}
+	return b.String()
+}
+
+// OrgIndent returns the org-mode text org as an indented outline, reversing
+// OrgOutline.  A heading with n stars is indented by n-1 copies of unit.
+// Other non-blank lines, such as the body of a heading, are indented by one
+// more unit than the heading they follow.  Blank lines are preserved.
+func OrgIndent(org, unit string) string {
+	var b strings.Builder
+	depth := 0
+	for _, line := range strings.Split(strings.TrimRight(org, "\n"), "\n") {
+		if isBlank(line) {
+			b.WriteByte('\n')
+			continue
+		}
+		text := strings.TrimLeft(line, " \t")
+		n := depth
+		if stars := len(line) - len(strings.TrimLeft(line, "*")); stars > 0 && (stars == len(line) || line[stars] == ' ') {
+			depth = stars
+			n = depth - 1
+			text = strings.TrimLeft(line[stars:], " ")
+		}
+		if text = strings.TrimRight(text, " \t\r"); text != "" {
+			b.WriteString(strings.Repeat(unit, n))
+			b.WriteString(text)
+		}
+		b.WriteByte('\n')
+	}
+	if b.Len() == 1 {
+		return ""
+	}
+	return b.String()
+}
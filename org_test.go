@@ -0,0 +1,56 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "testing"
+
+func TestOrgOutline(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{},
+		{in: "a\n  b\n    c\n  d\ne\n", out: "* a\n** b\n*** c\n** d\n* e\n"},
+		{in: "\ta\n\t\tb\n\n\tc", out: "* a\n** b\n\n* c\n"},
+		// A partial dedent is a sibling of the closest shallower line.
+		{in: "a\n    b\n  c\n", out: "* a\n** b\n** c\n"},
+		{in: "  a\nb\n", out: "* a\n* b\n"},
+	} {
+		if got := OrgOutline(tt.in); got != tt.out {
+			t.Errorf("OrgOutline(%q) got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestOrgIndent(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{},
+		{in: "* a\n** b\n*** c\n** d\n* e\n", out: "a\n  b\n    c\n  d\ne\n"},
+		{in: "preamble\n* a\nbody of a\n** b\n   more\n", out: "preamble\na\n  body of a\n  b\n    more\n"},
+		{in: "* a\n\n*bold* text\n**\n", out: "a\n\n  *bold* text\n\n"},
+	} {
+		if got := OrgIndent(tt.in, "  "); got != tt.out {
+			t.Errorf("OrgIndent(%q) got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestOrgRoundTrip(t *testing.T) {
+	in := "a\n  b\n    c\n  d\n\ne\n"
+	if got := OrgIndent(OrgOutline(in), "  "); got != in {
+		t.Errorf("got %q, want %q", got, in)
+	}
+}
@@ -0,0 +1,132 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "io"
+
+// An outbuf accumulates the output of a transforming writer along with enough
+// information to map a short write of the output back to the number of input
+// bytes it represents.  This is needed to honor the io.Writer contract when
+// the underlying writer does not accept everything we give it.
+type outbuf struct {
+	buf  []byte
+	segs []seg
+}
+
+// A seg is a run of out bytes of output that represent in bytes of input.
+// Copied input has out == in, added bytes have in == 0 and dropped input has
+// out == 0.
+type seg struct {
+	out, in int
+}
+
+// copy appends buf, which came from the input, to the output.
+func (o *outbuf) copy(buf []byte) {
+	if len(buf) == 0 {
+		return
+	}
+	o.buf = append(o.buf, buf...)
+	if n := len(o.segs) - 1; n >= 0 && o.segs[n].out == o.segs[n].in && o.segs[n].out > 0 {
+		o.segs[n].out += len(buf)
+		o.segs[n].in += len(buf)
+		return
+	}
+	o.segs = append(o.segs, seg{out: len(buf), in: len(buf)})
+}
+
+// copyByte is copy for a single byte.
+func (o *outbuf) copyByte(c byte) {
+	o.buf = append(o.buf, c)
+	if n := len(o.segs) - 1; n >= 0 && o.segs[n].out == o.segs[n].in && o.segs[n].out > 0 {
+		o.segs[n].out++
+		o.segs[n].in++
+		return
+	}
+	o.segs = append(o.segs, seg{out: 1, in: 1})
+}
+
+// add appends buf, which did not come from the input, to the output.
+func (o *outbuf) add(buf []byte) {
+	if len(buf) == 0 {
+		return
+	}
+	o.buf = append(o.buf, buf...)
+	o.added(len(buf))
+}
+
+// addString is add for a string.
+func (o *outbuf) addString(s string) {
+	if len(s) == 0 {
+		return
+	}
+	o.buf = append(o.buf, s...)
+	o.added(len(s))
+}
+
+func (o *outbuf) added(n int) {
+	if i := len(o.segs) - 1; i >= 0 && o.segs[i].in == 0 {
+		o.segs[i].out += n
+		return
+	}
+	o.segs = append(o.segs, seg{out: n})
+}
+
+// drop records that n bytes of input were consumed without producing output.
+func (o *outbuf) drop(n int) {
+	if n == 0 {
+		return
+	}
+	if i := len(o.segs) - 1; i >= 0 && o.segs[i].out == 0 {
+		o.segs[i].in += n
+		return
+	}
+	o.segs = append(o.segs, seg{in: n})
+}
+
+// consumed returns the number of input bytes represented by the first n bytes
+// of output.  Input that was dropped is considered consumed once all the output
+// before it has been written.
+func (o *outbuf) consumed(n int) int {
+	in := 0
+	for _, s := range o.segs {
+		switch {
+		case n >= s.out:
+			n -= s.out
+			in += s.in
+		case s.out == s.in:
+			return in + n
+		default:
+			return in
+		}
+	}
+	return in
+}
+
+// writeTo writes the accumulated output to w and resets o.  It returns the
+// number of input bytes that made it to w.
+func (o *outbuf) writeTo(w io.Writer) (int, error) {
+	var n int
+	var err error
+	if len(o.buf) > 0 {
+		n, err = w.Write(o.buf)
+		if err == nil && n < len(o.buf) {
+			err = io.ErrShortWrite
+		}
+	}
+	in := o.consumed(n)
+	o.buf = o.buf[:0]
+	o.segs = o.segs[:0]
+	return in, err
+}
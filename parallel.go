@@ -0,0 +1,83 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"sync"
+)
+
+// DefaultParallelThreshold is the threshold used by WithParallel when it is
+// passed a threshold that is not positive.
+const DefaultParallelThreshold = 1 << 20
+
+// WithParallel causes inputs of at least threshold bytes to be split at line
+// boundaries and indented by multiple goroutines, one per CPU, before being
+// written as a single buffer.  It may be passed to String and Bytes as well as
+// New.  The output is identical to indenting on a single goroutine.
+func WithParallel(threshold int) Option {
+	if threshold <= 0 {
+		threshold = DefaultParallelThreshold
+	}
+	return func(in *indenter) {
+		in.s.opts().parallel = threshold
+	}
+}
+
+// appendIndentParallel is appendIndent split into up to n pieces that are
+// indented concurrently.
+func appendIndentParallel(dst, buf, prefix, postfix []byte, sol bool, n int) []byte {
+	if n < 2 {
+		return appendIndent(dst, buf, prefix, postfix, sol)
+	}
+	// Split buf into pieces that end in a newline, except perhaps the
+	// last.  Every piece other than the first starts a line.
+	var pieces [][]byte
+	size := len(buf) / n
+	for len(pieces) < n-1 && size < len(buf) {
+		x := bytes.IndexByte(buf[size:], '\n')
+		if x < 0 {
+			break
+		}
+		pieces = append(pieces, buf[:size+x+1])
+		buf = buf[size+x+1:]
+	}
+	if len(buf) > 0 {
+		pieces = append(pieces, buf)
+	}
+
+	sizes := make([]int, len(pieces))
+	need := 0
+	for i, p := range pieces {
+		sizes[i] = indentSize(p, prefix, postfix, sol || i > 0)
+		need += sizes[i]
+	}
+	start := len(dst)
+	dst = grow(dst, need)
+	dst = dst[:start+need]
+
+	var wg sync.WaitGroup
+	off := start
+	for i, p := range pieces {
+		wg.Add(1)
+		go func(out, p []byte, sol bool) {
+			fillIndent(out, p, prefix, postfix, sol)
+			wg.Done()
+		}(dst[off:off+sizes[i]], p, sol || i > 0)
+		off += sizes[i]
+	}
+	wg.Wait()
+	return dst
+}
@@ -0,0 +1,80 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAppendIndentParallel(t *testing.T) {
+	inputs := []string{
+		"a",
+		"a\n",
+		"\n\n\n",
+		"abc\ndef\nghi",
+		"abc\ndef\nghi\n",
+		strings.Repeat("a line of text\n", 100),
+		strings.Repeat("x", 100) + "\n" + strings.Repeat("y\n", 10),
+	}
+	for _, in := range inputs {
+		for _, sol := range []bool{false, true} {
+			for _, postfix := range []string{"", " |"} {
+				want := appendIndent([]byte("dst"), []byte(in), []byte("> "), []byte(postfix), sol)
+				for n := 0; n < 9; n++ {
+					got := appendIndentParallel([]byte("dst"), []byte(in), []byte("> "), []byte(postfix), sol, n)
+					if !bytes.Equal(got, want) {
+						t.Errorf("%q sol=%v postfix=%q n=%d got %q, want %q", in, sol, postfix, n, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestWithParallel(t *testing.T) {
+	input := strings.Repeat("some text\n", 1000)
+	want := String("\t", input)
+	if got := String("\t", input, WithParallel(100)); got != want {
+		t.Errorf("String with WithParallel did not match")
+	}
+	if got := string(Bytes([]byte("\t"), []byte(input), WithParallel(100))); got != want {
+		t.Errorf("Bytes with WithParallel did not match")
+	}
+	var buf bytes.Buffer
+	New(&buf, "\t", WithParallel(100)).Write([]byte(input))
+	if got := buf.String(); got != want {
+		t.Errorf("New with WithParallel did not match")
+	}
+}
+
+func BenchmarkParallel(b *testing.B) {
+	input := []byte(strings.Repeat("a line of text that is not too short\n", 1<<16))
+	prefix := []byte("\t")
+	for _, parallel := range []bool{false, true} {
+		b.Run(fmt.Sprintf("parallel=%v", parallel), func(b *testing.B) {
+			var opts []Option
+			if parallel {
+				opts = append(opts, WithParallel(0))
+			}
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				Bytes(prefix, input, opts...)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// An IndentedStringer is a value that can render itself as one or more lines
+// of text.  WriteIndented writes the text to w, which is normally a writer
+// returned by New.  A value with children typically writes its own line and
+// then calls Print with w and an additional prefix for each child.
+type IndentedStringer interface {
+	WriteIndented(w io.Writer) error
+}
+
+// Print writes v to w with each line started by prefix.  If v is an
+// IndentedStringer then its WriteIndented method is called, otherwise v is
+// formatted with its String method if it is a fmt.Stringer, or with %v.  Text
+// that does not end in a newline is followed by one.  Since w may itself be
+// an indenting writer, calling Print from within WriteIndented nests the
+// output of v below its parent:
+//
+//	func (n *Node) WriteIndented(w io.Writer) error {
+//		if _, err := fmt.Fprintln(w, n.Name); err != nil {
+//			return err
+//		}
+//		for _, c := range n.Children {
+//			if err := indent.Print(w, "  ", c); err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	}
+func Print(w io.Writer, prefix string, v interface{}) error {
+	iw := New(w, prefix)
+	if is, ok := v.(IndentedStringer); ok {
+		return is.WriteIndented(iw)
+	}
+	var s string
+	if st, ok := v.(fmt.Stringer); ok {
+		s = st.String()
+	} else {
+		s = fmt.Sprintf("%v", v)
+	}
+	if len(s) == 0 || s[len(s)-1] != '\n' {
+		s += "\n"
+	}
+	_, err := io.WriteString(iw, s)
+	return err
+}
+
+// With calls fn with a writer that prefixes the lines written to it with
+// prefix, as returned by New(w, prefix), and then finishes the writer.  If the
+// output of fn ends part way through a line, a newline is written to end it.
+// The writer is then closed, flushing any output it holds.  If prefix is
+// empty, fn is called with w itself, which is left as is.  With returns the
+// first error returned by fn or encountered while finishing the writer.  The
+// writer must not be used after fn returns.  With makes the nesting of output
+// apparent in the structure of the code that produces it:
+//
+//	indent.With(w, "  ", func(w io.Writer) error {
+//		_, err := fmt.Fprintln(w, "nested")
+//		return err
+//	})
+func With(w io.Writer, prefix string, fn func(io.Writer) error) error {
+	iw := New(w, prefix)
+	err := fn(iw)
+	// With an empty prefix New returns w, which belongs to the caller.
+	if in, ok := iw.(*indenter); ok && iw != w {
+		if !in.s.sol && err == nil {
+			_, err = iw.Write([]byte{'\n'})
+		}
+		if cerr := in.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Fprint formats its arguments as fmt.Print does and writes the result to w
+// with each line started by prefix.  It returns the number of bytes of
+// formatted text written, not counting the prefixes, and any error
+// encountered.
+func Fprint(w io.Writer, prefix string, a ...interface{}) (int, error) {
+	return fmt.Fprint(New(w, prefix), a...)
+}
+
+// Fprintf is like Fprint but formats its arguments as fmt.Printf does.
+func Fprintf(w io.Writer, prefix, format string, a ...interface{}) (int, error) {
+	return fmt.Fprintf(New(w, prefix), format, a...)
+}
+
+// Fprintln is like Fprint but formats its arguments as fmt.Println does.
+func Fprintln(w io.Writer, prefix string, a ...interface{}) (int, error) {
+	return fmt.Fprintln(New(w, prefix), a...)
+}
+
+// Sprint formats its arguments as fmt.Print does and returns the result with
+// each line started by prefix.
+func Sprint(prefix string, a ...interface{}) string {
+	b := getPrintBuffer()
+	fmt.Fprint(b, a...)
+	return sprint(prefix, b)
+}
+
+// Sprintf is like Sprint but formats its arguments as fmt.Printf does.
+func Sprintf(prefix, format string, a ...interface{}) string {
+	b := getPrintBuffer()
+	fmt.Fprintf(b, format, a...)
+	return sprint(prefix, b)
+}
+
+// Sprintln is like Sprint but formats its arguments as fmt.Println does.
+func Sprintln(prefix string, a ...interface{}) string {
+	b := getPrintBuffer()
+	fmt.Fprintln(b, a...)
+	return sprint(prefix, b)
+}
+
+// printBuffers holds the buffers Sprint and friends format into.  Only the
+// indented result is allocated.
+var printBuffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getPrintBuffer() *bytes.Buffer {
+	b := printBuffers.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+// sprint returns the contents of b indented by prefix and returns b to
+// printBuffers.
+func sprint(prefix string, b *bytes.Buffer) string {
+	var s string
+	if len(prefix) == 0 || b.Len() == 0 {
+		s = b.String()
+	} else {
+		s = b2s(indent(b.Bytes(), s2b(prefix), nil, true))
+	}
+	printBuffers.Put(b)
+	return s
+}
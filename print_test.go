@@ -0,0 +1,226 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type printNode struct {
+	name     string
+	children []interface{}
+}
+
+func (n *printNode) WriteIndented(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, n.name); err != nil {
+		return err
+	}
+	for _, c := range n.children {
+		if err := Print(w, "  ", c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type printStringer struct{}
+
+func (printStringer) String() string { return "one\ntwo" }
+
+type printFailer struct{}
+
+func (printFailer) WriteIndented(w io.Writer) error { return errors.New("failed") }
+
+func TestPrint(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		prefix string
+		v      interface{}
+		out    string
+		err    string
+	}{
+		{
+			name: "value",
+			v:    42,
+			out:  "42\n",
+		},
+		{
+			name:   "prefixed",
+			prefix: "> ",
+			v:      "hello\n",
+			out:    "> hello\n",
+		},
+		{
+			name:   "stringer",
+			prefix: "# ",
+			v:      printStringer{},
+			out:    "# one\n# two\n",
+		},
+		{
+			name:   "empty",
+			prefix: "# ",
+			v:      "",
+			out:    "# \n",
+		},
+		{
+			name: "tree",
+			v: &printNode{
+				name: "root",
+				children: []interface{}{
+					&printNode{name: "a", children: []interface{}{"leaf", printStringer{}}},
+					&printNode{name: "b"},
+					3.5,
+				},
+			},
+			out: "root\n  a\n    leaf\n    one\n    two\n  b\n  3.5\n",
+		},
+		{
+			name: "error",
+			v:    &printNode{name: "root", children: []interface{}{printFailer{}}},
+			out:  "root\n",
+			err:  "failed",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			err := Print(&buf, tt.prefix, tt.v)
+			var errs string
+			if err != nil {
+				errs = err.Error()
+			}
+			if errs != tt.err {
+				t.Errorf("got error %q, want %q", errs, tt.err)
+			}
+			if got := buf.String(); got != tt.out {
+				t.Errorf("got %q, want %q", got, tt.out)
+			}
+		})
+	}
+}
+
+func TestFprint(t *testing.T) {
+	var buf strings.Builder
+	check := func(name string, n int, err error, want int, out string) {
+		t.Helper()
+		if n != want || err != nil {
+			t.Errorf("%s got %d, %v, want %d, nil", name, n, err, want)
+		}
+		if got := buf.String(); got != out {
+			t.Errorf("%s got %q, want %q", name, got, out)
+		}
+		buf.Reset()
+	}
+	n, err := Fprint(&buf, "> ", "a\n", 1, 2)
+	check("Fprint", n, err, 5, "> a\n> 1 2")
+	n, err = Fprintf(&buf, "> ", "%d\n%s\n", 1, "two")
+	check("Fprintf", n, err, 6, "> 1\n> two\n")
+	n, err = Fprintln(&buf, "> ", "a\nb", 3)
+	check("Fprintln", n, err, 6, "> a\n> b 3\n")
+}
+
+func TestSprint(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Sprint", Sprint("> ", "a\n", 1, 2), "> a\n> 1 2"},
+		{"Sprint empty", Sprint("> "), ""},
+		{"Sprint no prefix", Sprint("", "a\n", "b"), "a\nb"},
+		{"Sprintf", Sprintf("> ", "%d\n%s\n", 1, "two"), "> 1\n> two\n"},
+		{"Sprintln", Sprintln("\t", "a\nb", 3), "\ta\n\tb 3\n"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestSprintfAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		Sprintf("> ", "line %s\nline %s\n", "one", "two")
+	})
+	// Only the result is allocated.
+	if allocs > 1 {
+		t.Errorf("got %v allocations, want 1", allocs)
+	}
+}
+
+func TestWith(t *testing.T) {
+	var buf strings.Builder
+	w := New(&buf, "> ")
+	io.WriteString(w, "start\n")
+	err := With(w, "  ", func(w io.Writer) error {
+		io.WriteString(w, "a\n")
+		return With(w, "- ", func(w io.Writer) error {
+			_, err := io.WriteString(w, "b\nc")
+			return err
+		})
+	})
+	if err != nil {
+		t.Errorf("With returned %v", err)
+	}
+	io.WriteString(w, "end\n")
+	if got, want := buf.String(), "> start\n>   a\n>   - b\n>   - c\n> end\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	bad := errors.New("bad")
+	buf.Reset()
+	err = With(&buf, "", func(w io.Writer) error {
+		io.WriteString(w, "x")
+		return bad
+	})
+	if err != bad {
+		t.Errorf("With returned %v, want %v", err, bad)
+	}
+	if got := buf.String(); got != "x" {
+		t.Errorf("got %q, want %q", got, "x")
+	}
+
+	// An indenter passed with an empty prefix is not finished or closed.
+	cw := &closeWriter{}
+	var events []NestEvent
+	outer := New(cw, "> ", WithCloseOutput())
+	w = New(outer, "  ", WithNestHook(func(ev NestEvent) {
+		events = append(events, ev)
+	}))
+	events = nil
+	for _, w := range []io.Writer{w, outer} {
+		err = With(w, "", func(w io.Writer) error {
+			_, err := io.WriteString(w, "x")
+			return err
+		})
+		if err != nil {
+			t.Errorf("With returned %v", err)
+		}
+	}
+	if got, want := cw.String(), ">   xx"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if cw.closed != 0 {
+		t.Errorf("underlying writer closed %d times", cw.closed)
+	}
+	if len(events) != 0 {
+		t.Errorf("got nest events %v", events)
+	}
+}
@@ -0,0 +1,90 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"fmt"
+	"io"
+)
+
+// A Printer prints indented text, such as generated code, to a writer.  The
+// depth of indentation is changed by In and Out.  A change of depth ends a
+// line that has been started, so the output at the new depth always starts
+// at the start of a line.  A Printer remembers the first error it encounters,
+// which is returned by Err, and prints nothing after an error.  A Printer is
+// not safe for concurrent use.
+type Printer struct {
+	lw  *LevelWriter
+	err error
+}
+
+// NewPrinter returns a Printer at depth 0 that writes to w and indents by unit
+// per level of depth.
+func NewPrinter(w io.Writer, unit string) *Printer {
+	return &Printer{lw: NewLevelWriter(w, unit)}
+}
+
+// Print formats its arguments as fmt.Print does.
+func (p *Printer) Print(a ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprint(p.lw, a...)
+	}
+}
+
+// Printf formats its arguments as fmt.Printf does.
+func (p *Printer) Printf(format string, a ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.lw, format, a...)
+	}
+}
+
+// Println formats its arguments as fmt.Println does.
+func (p *Printer) Println(a ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintln(p.lw, a...)
+	}
+}
+
+// In increases the depth by one level.
+func (p *Printer) In() {
+	p.endLine()
+	p.lw.Indent()
+}
+
+// Out decreases the depth by one level.  Calling Out at depth 0 is an error
+// reported by Err.
+func (p *Printer) Out() {
+	p.endLine()
+	if err := p.lw.Outdent(); err != nil && p.err == nil {
+		p.err = err
+	}
+}
+
+// Depth returns the current depth.
+func (p *Printer) Depth() int {
+	return p.lw.Depth()
+}
+
+// Err returns the first error encountered by p.
+func (p *Printer) Err() error {
+	return p.err
+}
+
+// endLine ends the current line if it has been started.
+func (p *Printer) endLine() {
+	if p.err == nil && !p.lw.levels[p.lw.depth].s.sol {
+		_, p.err = io.WriteString(p.lw, "\n")
+	}
+}
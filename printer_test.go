@@ -0,0 +1,65 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestPrinter(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewPrinter(&buf, "\t")
+	p.Printf("type %s struct {", "T")
+	p.In()
+	p.Println("A int")
+	p.Print("B ", "string")
+	p.Out()
+	p.Println("}")
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if d := p.Depth(); d != 0 {
+		t.Errorf("got depth %d, want 0", d)
+	}
+	want := "type T struct {\n\tA int\n\tB string\n}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	p.Out()
+	p.Println("not printed")
+	if err := p.Err(); err != ErrOutdent {
+		t.Errorf("got error %v, want %v", err, ErrOutdent)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got %q after error, want %q", got, want)
+	}
+}
+
+func TestPrinterError(t *testing.T) {
+	fw := &fakeWriter{left: 3}
+	p := NewPrinter(fw, "  ")
+	p.Println("abcdef")
+	p.In()
+	p.Println("more")
+	if err := p.Err(); err != io.EOF {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+	if got := fw.buf.String(); got != "abc" {
+		t.Errorf("got %q, want %q", got, "abc")
+	}
+}
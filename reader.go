@@ -0,0 +1,210 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+)
+
+// A reader passes the text read from r through the writer w, which writes to
+// out by way of sink, and returns the contents of out.  WriteTo points sink
+// directly at its writer instead.
+type reader struct {
+	r     io.Reader
+	w     io.Writer
+	sink  readerSink
+	out   bytes.Buffer
+	chunk []byte
+	err   error
+}
+
+// A readerSink is the writer beneath the writer of a reader.  It counts the
+// bytes written to w.
+type readerSink struct {
+	w io.Writer
+	n int64
+}
+
+func (s *readerSink) Write(buf []byte) (int, error) {
+	n, err := s.w.Write(buf)
+	s.n += int64(n)
+	return n, err
+}
+
+// readChunk is the size of the reads made by a reader.
+const readChunk = 32 * 1024
+
+func newReader(r io.Reader, w func(io.Writer) io.Writer) *reader {
+	rd := &reader{r: r}
+	rd.sink.w = &rd.out
+	rd.w = w(&rd.sink)
+	return rd
+}
+
+// NewReader returns a reader that reads from r and returns the text with each
+// line started by prefix, as New would write it.  The text is indented as it
+// is read and at most one read from r is buffered at any time.  NewReader
+// returns r if prefix is the empty string.
+func NewReader(r io.Reader, prefix string) io.Reader {
+	if len(prefix) == 0 {
+		return r
+	}
+	return newReader(r, func(w io.Writer) io.Writer {
+		return New(w, prefix)
+	})
+}
+
+// NewDedentReader returns a reader that reads from r and returns the text with
+// prefix removed from the start of each line, as NewUnindent would write it.
+// Lines that do not start with prefix are returned unchanged.  If prefix is
+// the empty string then the leading spaces and tabs of the first non-blank
+// line read from r are used as the prefix.  Unlike Dedent, this does not
+// require reading all of r before returning the first line.
+func NewDedentReader(r io.Reader, prefix string) io.Reader {
+	return newReader(r, func(w io.Writer) io.Writer {
+		if len(prefix) > 0 {
+			return NewUnindent(w, prefix)
+		}
+		return &firstUnindenter{w: w}
+	})
+}
+
+// NewDedentReaderStrict is like NewDedentReader but every line read from r
+// must start with prefix, such as when the prefix is part of a framing
+// protocol rather than decoration.  This includes blank lines and a final
+// line without a newline.  When a line does not start with prefix the reader
+// returns the text before that line followed by an error that includes the
+// number, starting with 1, of the line and wraps ErrMissingPrefix.  Since
+// every line starts with the empty string, NewDedentReaderStrict returns r
+// if prefix is the empty string.
+func NewDedentReaderStrict(r io.Reader, prefix string) io.Reader {
+	if len(prefix) == 0 {
+		return r
+	}
+	return newReader(r, func(w io.Writer) io.Writer {
+		u := newUnindenter(w, prefix)
+		u.strict = true
+		return u
+	})
+}
+
+// A firstUnindenter holds on to the text written to it until it has seen the
+// leading whitespace of the first non-blank line.  It then passes the text
+// through a writer returned by NewUnindent with that whitespace as the prefix.
+type firstUnindenter struct {
+	w    io.Writer
+	u    io.Writer
+	held []byte
+}
+
+func (f *firstUnindenter) Write(buf []byte) (int, error) {
+	if f.u != nil {
+		return f.u.Write(buf)
+	}
+	f.held = append(f.held, buf...)
+	line := f.held
+	for {
+		x := bytes.IndexByte(line, '\n')
+		if x >= 0 && isBlank(b2s(line[:x+1])) {
+			line = line[x+1:]
+			continue
+		}
+		ws := bytes.TrimLeft(line, " \t")
+		if len(ws) == 0 || (ws[0] == '\r' && x < 0) {
+			// We do not yet know where the indentation ends.
+			return len(buf), nil
+		}
+		f.u = NewUnindent(f.w, string(line[:len(line)-len(ws)]))
+		break
+	}
+	held := f.held
+	f.held = nil
+	if _, err := f.u.Write(held); err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+// Flush writes any held text.
+func (f *firstUnindenter) Flush() error {
+	if f.u == nil {
+		held := f.held
+		f.held = nil
+		_, err := f.w.Write(held)
+		return err
+	}
+	if fl, ok := f.u.(interface{ Flush() error }); ok {
+		return fl.Flush()
+	}
+	return nil
+}
+
+// fill reads the next chunk from r and passes it through w.  It sets err when
+// r or w return an error.
+func (rd *reader) fill() {
+	if rd.chunk == nil {
+		rd.chunk = make([]byte, readChunk)
+	}
+	n, err := rd.r.Read(rd.chunk)
+	if _, werr := rd.w.Write(rd.chunk[:n]); werr != nil {
+		rd.err = werr
+		return
+	}
+	if err != nil {
+		if f, ok := rd.w.(interface{ Flush() error }); ok {
+			if ferr := f.Flush(); ferr != nil {
+				err = ferr
+			}
+		}
+		rd.err = err
+	}
+}
+
+func (rd *reader) Read(buf []byte) (int, error) {
+	for rd.out.Len() == 0 && rd.err == nil {
+		rd.fill()
+	}
+	if rd.out.Len() > 0 {
+		return rd.out.Read(buf)
+	}
+	return 0, rd.err
+}
+
+// WriteTo writes the rest of the text to w until the underlying reader
+// returns io.EOF or an error.  It is used by io.Copy.  The text is written to
+// w as each chunk is read rather than being buffered to be returned by Read.
+// It returns the number of bytes written to w.
+func (rd *reader) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	if rd.out.Len() > 0 {
+		n, err := rd.out.WriteTo(w)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	rd.sink.w = w
+	rd.sink.n = 0
+	for rd.err == nil {
+		rd.fill()
+	}
+	total += rd.sink.n
+	rd.sink.w = &rd.out
+	if rd.err == io.EOF {
+		return total, nil
+	}
+	return total, rd.err
+}
@@ -0,0 +1,198 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewReader(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		in     string
+		out    string
+	}{
+		{prefix: "> ", in: "", out: ""},
+		{prefix: "> ", in: "a", out: "> a"},
+		{prefix: "> ", in: "a\nb\n\nc\n", out: "> a\n> b\n> \n> c\n"},
+		{prefix: "", in: "a\nb\n", out: "a\nb\n"},
+	} {
+		for _, r := range []io.Reader{
+			strings.NewReader(tt.in),
+			iotest.OneByteReader(strings.NewReader(tt.in)),
+			iotest.DataErrReader(strings.NewReader(tt.in)),
+		} {
+			got, err := ioutil.ReadAll(iotest.OneByteReader(NewReader(r, tt.prefix)))
+			if err != nil {
+				t.Errorf("%q: %v", tt.in, err)
+			}
+			if string(got) != tt.out {
+				t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+			}
+		}
+	}
+}
+
+func TestNewReaderError(t *testing.T) {
+	r := io.MultiReader(strings.NewReader("a\nb"), iotest.TimeoutReader(strings.NewReader("x")))
+	r = NewReader(r, "> ")
+	got, err := ioutil.ReadAll(r)
+	if err != iotest.ErrTimeout {
+		t.Errorf("got error %v, want %v", err, iotest.ErrTimeout)
+	}
+	if want := "> a\n> bx"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewDedentReader(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		in     string
+		out    string
+	}{
+		{prefix: "> ", in: "", out: ""},
+		{prefix: "> ", in: "> a\n>b\n> > c\n> ", out: "a\n>b\n> c\n"},
+		{prefix: "> ", in: "> a\n>", out: "a\n>"},
+		{in: "", out: ""},
+		{in: "\n \n", out: "\n \n"},
+		{in: "  ", out: "  "},
+		{in: "\n\t\ta\n\t\t\tb\n\tc\n\t\td", out: "\na\n\tb\n\tc\nd"},
+		{in: "    a\r\n    b\r\n", out: "a\r\nb\r\n"},
+	} {
+		for _, r := range []io.Reader{
+			strings.NewReader(tt.in),
+			iotest.OneByteReader(strings.NewReader(tt.in)),
+		} {
+			got, err := ioutil.ReadAll(NewDedentReader(r, tt.prefix))
+			if err != nil {
+				t.Errorf("%q: %v", tt.in, err)
+			}
+			if string(got) != tt.out {
+				t.Errorf("%q, %q: got %q, want %q", tt.prefix, tt.in, got, tt.out)
+			}
+		}
+	}
+}
+
+// writeCounter counts the writes made to it.  It is not an io.ReaderFrom so
+// io.Copy must use the WriterTo of its source, or a buffer of its own.
+type writeCounter struct {
+	buf    strings.Builder
+	writes int
+}
+
+func (w *writeCounter) Write(buf []byte) (int, error) {
+	w.writes++
+	return w.buf.Write(buf)
+}
+
+func TestReaderWriteTo(t *testing.T) {
+	in := strings.Repeat("> a line\n>\tanother\n", readChunk/10)
+	for _, tt := range []struct {
+		name string
+		r    func(io.Reader) io.Reader
+		want string
+	}{
+		{"NewReader", func(r io.Reader) io.Reader { return NewReader(r, "| ") }, String("| ", in)},
+		{"NewDedentReader", func(r io.Reader) io.Reader { return NewDedentReader(r, "> ") }, strings.Replace(in, "> ", "", -1)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.r(strings.NewReader(in))
+			if _, ok := r.(io.WriterTo); !ok {
+				t.Fatalf("%T is not an io.WriterTo", r)
+			}
+			var w writeCounter
+			n, err := io.Copy(&w, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := w.buf.String(); got != tt.want {
+				t.Errorf("got %d bytes, want %d", len(got), len(tt.want))
+			}
+			if n != int64(len(tt.want)) {
+				t.Errorf("io.Copy got %d, want %d", n, len(tt.want))
+			}
+			// One write for each chunk read and nothing buffered for Read.
+			if max := len(in)/readChunk + 1; w.writes > max {
+				t.Errorf("got %d writes, want at most %d", w.writes, max)
+			}
+			if c := r.(*reader).out.Cap(); c != 0 {
+				t.Errorf("WriteTo buffered output for Read (cap %d)", c)
+			}
+		})
+	}
+
+	// Output already read into the buffer is written first.
+	r := NewReader(strings.NewReader("a\nb\nc\n"), "> ")
+	buf := make([]byte, 3)
+	io.ReadFull(r, buf)
+	var w strings.Builder
+	w.Write(buf)
+	if _, err := io.Copy(&w, r); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w.String(), "> a\n> b\n> c\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewDedentReaderStrict(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		out  string
+		line int
+	}{
+		{in: "", out: ""},
+		{in: "> a\n> b\n> ", out: "a\nb\n"},
+		{in: "> a\n> b", out: "a\nb"},
+		{in: "> a\nb\n> c\n", out: "a\n", line: 2},
+		{in: "> a\n\n", out: "a\n", line: 2},
+		{in: "> a\n>b\n", out: "a\n", line: 2},
+		{in: "x\n", out: "", line: 1},
+		{in: "> a\n>", out: "a\n", line: 2},
+	} {
+		for _, r := range []io.Reader{
+			strings.NewReader(tt.in),
+			iotest.OneByteReader(strings.NewReader(tt.in)),
+		} {
+			got, err := ioutil.ReadAll(NewDedentReaderStrict(r, "> "))
+			if string(got) != tt.out {
+				t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+			}
+			var want error
+			if tt.line > 0 {
+				want = fmt.Errorf("indent: line %d: %w", tt.line, ErrMissingPrefix)
+			}
+			switch {
+			case err == nil && want == nil:
+			case err == nil || want == nil || err.Error() != want.Error():
+				t.Errorf("%q: got error %v, want %v", tt.in, err, want)
+			case !errors.Is(err, ErrMissingPrefix):
+				t.Errorf("%q: error %v does not wrap ErrMissingPrefix", tt.in, err)
+			}
+		}
+	}
+	r := strings.NewReader("a\n")
+	if NewDedentReaderStrict(r, "") != r {
+		t.Errorf("NewDedentReaderStrict with an empty prefix did not return r")
+	}
+}
@@ -0,0 +1,80 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"sort"
+	"strings"
+)
+
+// RSTLiteral returns body as a reStructuredText literal block.  The block is
+// introduced by the paragraph intro ending with "::", or by a line containing
+// just "::" if intro is empty.  The body is dedented and then indented by four
+// spaces.  Leading and trailing blank lines of body are removed and the
+// result, which ends in a newline, may be followed directly by a blank line
+// and the next paragraph.
+func RSTLiteral(intro, body string) string {
+	intro = strings.TrimRight(intro, " \t\n")
+	switch {
+	case intro == "":
+		intro = "::"
+	case strings.HasSuffix(intro, "::"):
+	case strings.HasSuffix(intro, ":"):
+		intro += ":"
+	default:
+		intro += "::"
+	}
+	body = reindent("    ", body)
+	if body == "" {
+		return intro + "\n"
+	}
+	return intro + "\n\n" + body
+}
+
+// RSTDirective returns a reStructuredText directive named name, such as
+// "note" or "code-block", with the arguments args.  The options, which are
+// written in sorted order, and the body are indented by three spaces to line
+// up with the name of the directive.  An option with an empty value is written
+// as a flag.  The body is dedented before it is indented, so the body of a
+// nested directive may be the result of RSTDirective.  The result ends in a
+// newline.
+func RSTDirective(name, args string, options map[string]string, body string) string {
+	var b strings.Builder
+	b.WriteString(".. ")
+	b.WriteString(name)
+	b.WriteString("::")
+	if args != "" {
+		b.WriteByte(' ')
+		b.WriteString(args)
+	}
+	b.WriteByte('\n')
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString("   :" + k + ":")
+		if v := options[k]; v != "" {
+			b.WriteString(" " + v)
+		}
+		b.WriteByte('\n')
+	}
+	if body = reindent("   ", body); body != "" {
+		b.WriteByte('\n')
+		b.WriteString(body)
+	}
+	return b.String()
+}
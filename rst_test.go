@@ -0,0 +1,69 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "testing"
+
+func TestRSTLiteral(t *testing.T) {
+	for _, tt := range []struct {
+		intro, body string
+		out         string
+	}{
+		{body: "x := 1\n", out: "::\n\n    x := 1\n"},
+		{intro: "For example", body: "\tif x {\n\t\ty()\n\t}\n", out: "For example::\n\n    if x {\n    \ty()\n    }\n"},
+		{intro: "Run:", body: "$ go test\n\n$ go vet", out: "Run::\n\n    $ go test\n\n    $ go vet\n"},
+		{intro: "Already::", body: "a", out: "Already::\n\n    a\n"},
+		{intro: "Empty", out: "Empty::\n"},
+	} {
+		if got := RSTLiteral(tt.intro, tt.body); got != tt.out {
+			t.Errorf("RSTLiteral(%q, %q) got %q, want %q", tt.intro, tt.body, got, tt.out)
+		}
+	}
+}
+
+func TestRSTDirective(t *testing.T) {
+	code := RSTDirective("code-block", "go", map[string]string{
+		"linenos": "",
+		"caption": "Example",
+	}, "\n  fmt.Println()\n")
+	want := `.. code-block:: go
+   :caption: Example
+   :linenos:
+
+   fmt.Println()
+`
+	if code != want {
+		t.Errorf("got:\n%s\nwant:\n%s", code, want)
+	}
+
+	got := RSTDirective("note", "", nil, "Nested:\n\n"+code)
+	want = `.. note::
+
+   Nested:
+
+   .. code-block:: go
+      :caption: Example
+      :linenos:
+
+      fmt.Println()
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got, want := RSTDirective("contents", "", nil, ""), ".. contents::\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
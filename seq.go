@@ -0,0 +1,48 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+//go:build go1.23
+
+package indent
+
+import (
+	"iter"
+	"strings"
+)
+
+// Seq returns a sequence that yields the lines of the sequence lines, each
+// prefixed by prefix.  A line that contains newlines is split into multiple
+// lines as Lines does.  Lines are prefixed as they are pulled from lines, so
+// Seq can be placed in a streaming pipeline, such as one that reads lines with
+// a bufio.Scanner, without collecting the lines first.
+func Seq(prefix string, lines iter.Seq[string]) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for line := range lines {
+			line = strings.TrimSuffix(line, "\n")
+			for {
+				x := strings.IndexByte(line, '\n')
+				if x < 0 {
+					break
+				}
+				if !yield(prefix + line[:x]) {
+					return
+				}
+				line = line[x+1:]
+			}
+			if !yield(prefix + line) {
+				return
+			}
+		}
+	}
+}
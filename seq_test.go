@@ -0,0 +1,59 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+//go:build go1.23
+
+package indent
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	for _, tt := range []struct {
+		in, out []string
+	}{
+		{in: nil, out: nil},
+		{in: []string{"a", "", "b"}, out: []string{"> a", "> ", "> b"}},
+		{in: []string{"a\nb", "c\n"}, out: []string{"> a", "> b", "> c"}},
+	} {
+		got := slices.Collect(Seq("> ", slices.Values(tt.in)))
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.out) {
+			t.Errorf("Seq(%q) got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+
+	// Stopping early stops pulling lines.
+	pulled := 0
+	lines := func(yield func(string) bool) {
+		for _, line := range []string{"a\nb", "c", "d"} {
+			pulled++
+			if !yield(line) {
+				return
+			}
+		}
+	}
+	var got []string
+	for line := range Seq("> ", lines) {
+		got = append(got, line)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if pulled != 1 || fmt.Sprintf("%q", got) != `["> a" "> b"]` {
+		t.Errorf("got %q after pulling %d lines", got, pulled)
+	}
+}
@@ -0,0 +1,102 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// A Sharded indents output from many goroutines to a single io.Writer.  Each
+// goroutine writes to its own Shard, which indents and buffers what is written
+// to it.  Only complete lines are passed on to the underlying writer, so lines
+// from different shards are never interleaved.  Lines are written in the order
+// they are completed.  The shared lock is only taken when a write to a shard
+// completes one or more lines, and the indenting is done outside of the lock.
+type Sharded struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix []byte
+	err    error
+}
+
+// NewSharded returns a Sharded that writes to w, prefixing each line with
+// prefix.
+func NewSharded(w io.Writer, prefix string) *Sharded {
+	return &Sharded{w: w, prefix: []byte(prefix)}
+}
+
+// Shard returns a new shard of s.  A Shard must only be used by one goroutine
+// at a time.
+func (s *Sharded) Shard() *Shard {
+	return &Shard{s: s, sol: true}
+}
+
+// write writes buf to the underlying writer.  Once a write has failed all
+// further writes return the same error.
+func (s *Sharded) write(buf []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	n, err := s.w.Write(buf)
+	count(&writes, 1)
+	if err == nil && n < len(buf) {
+		err = io.ErrShortWrite
+	}
+	s.err = err
+	return err
+}
+
+// A Shard is an io.Writer for a single goroutine that is part of a Sharded.
+type Shard struct {
+	s   *Sharded
+	buf []byte // indented output not yet written
+	sol bool
+}
+
+// Write indents buf and writes any lines it completes to the underlying writer.
+// As with a bufio.Writer, a partial line is held until it is completed or the
+// shard is flushed.  Once an error has been returned by the underlying writer
+// all writes to all shards of the Sharded return that error.
+func (sh *Shard) Write(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	sh.buf = appendIndent(sh.buf, buf, sh.s.prefix, nil, sh.sol)
+	sh.sol = buf[len(buf)-1] == '\n'
+	x := bytes.LastIndexByte(sh.buf, '\n')
+	if x < 0 {
+		return len(buf), nil
+	}
+	err := sh.s.write(sh.buf[:x+1])
+	sh.buf = sh.buf[:copy(sh.buf, sh.buf[x+1:])]
+	if err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+// Flush writes any partial line held by sh to the underlying writer.
+func (sh *Shard) Flush() error {
+	if len(sh.buf) == 0 {
+		return nil
+	}
+	err := sh.s.write(sh.buf)
+	sh.buf = sh.buf[:0]
+	return err
+}
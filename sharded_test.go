@@ -0,0 +1,105 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestShard(t *testing.T) {
+	for _, tt := range []struct {
+		in    []string
+		out   string
+		flush string
+	}{
+		{in: []string{"a"}, out: "", flush: "> a"},
+		{in: []string{"a\n"}, out: "> a\n"},
+		{in: []string{"a", "b\nc"}, out: "> ab\n", flush: "> ab\n> c"},
+		{in: []string{"a\n", "\n", "b\n"}, out: "> a\n> \n> b\n"},
+	} {
+		var buf bytes.Buffer
+		sh := NewSharded(&buf, "> ").Shard()
+		for _, s := range tt.in {
+			if n, err := sh.Write([]byte(s)); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+		if tt.flush == "" {
+			tt.flush = tt.out
+		}
+		if err := sh.Flush(); err != nil {
+			t.Errorf("%q: Flush: %v", tt.in, err)
+		}
+		if got := buf.String(); got != tt.flush {
+			t.Errorf("%q: after Flush got %q, want %q", tt.in, got, tt.flush)
+		}
+	}
+}
+
+func TestShardError(t *testing.T) {
+	s := NewSharded(&fakeWriter{left: 3}, "> ")
+	sh1, sh2 := s.Shard(), s.Shard()
+	if _, err := sh1.Write([]byte("abc\n")); err != io.EOF {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+	if _, err := sh2.Write([]byte("def\n")); err != io.EOF {
+		t.Errorf("second shard got error %v, want %v", err, io.EOF)
+	}
+}
+
+func TestShardedConcurrent(t *testing.T) {
+	const goroutines = 8
+	const lines = 100
+
+	var buf bytes.Buffer
+	s := NewSharded(&buf, "> ")
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			sh := s.Shard()
+			for i := 0; i < lines; i++ {
+				// Write each line in two pieces to make sure
+				// partial lines are never interleaved.
+				fmt.Fprintf(sh, "goroutine %d ", g)
+				fmt.Fprintf(sh, "line %d\n", i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var want []string
+	for g := 0; g < goroutines; g++ {
+		for i := 0; i < lines; i++ {
+			want = append(want, fmt.Sprintf("> goroutine %d line %d", g, i))
+		}
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got lines:\n%s\nwant lines:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,198 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// A heredoc is a here-document found in a shell script.
+type heredoc struct {
+	word  string // the delimiting word
+	strip bool   // <<- was used so leading tabs are stripped
+}
+
+// A shellWriter indents shell scripts without disturbing here-documents.
+type shellWriter struct {
+	w       io.Writer
+	prefix  []byte
+	tabs    bool // prefix consists only of tabs
+	out     outbuf
+	err     error
+	sol     bool
+	line    []byte    // the current line
+	pending []heredoc // here-documents whose bodies have not started
+	docs    []heredoc // here-documents we are in the body of
+}
+
+// NewShell returns a writer that prefixes each line of the shell script written
+// to it with prefix and writes the results to w.  The bodies of here-documents
+// (<<WORD) are left verbatim, including their terminating line, so the script
+// still works after it is indented.  The bodies of here-documents using <<-WORD
+// are also prefixed if prefix consists only of tabs, as the shell strips
+// leading tabs from them.  NewShell returns w if prefix is the empty string.
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewShell(w io.Writer, prefix string) io.Writer {
+	if len(prefix) == 0 {
+		return w
+	}
+	return &shellWriter{
+		w:      w,
+		prefix: []byte(prefix),
+		tabs:   strings.Trim(prefix, "\t") == "",
+		sol:    true,
+	}
+}
+
+func (s *shellWriter) Write(buf []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	for len(buf) > 0 {
+		if s.sol {
+			s.sol = false
+			if len(s.docs) == 0 || (s.docs[0].strip && s.tabs) {
+				s.out.add(s.prefix)
+			}
+		}
+		line := buf
+		x := bytes.IndexByte(buf, '\n')
+		if x >= 0 {
+			line = buf[:x+1]
+		}
+		s.out.copy(line)
+		s.line = append(s.line, line...)
+		buf = buf[len(line):]
+		if x >= 0 {
+			s.endLine()
+			s.sol = true
+		}
+	}
+	n, err := s.out.writeTo(s.w)
+	s.err = err
+	return n, err
+}
+
+// endLine is called with the complete line, including its newline, in s.line.
+func (s *shellWriter) endLine() {
+	line := s.line[:len(s.line)-1]
+	s.line = s.line[:0]
+	if len(s.docs) > 0 {
+		if s.docs[0].strip {
+			line = bytes.TrimLeft(line, "\t")
+		}
+		if string(line) == s.docs[0].word {
+			s.docs = s.docs[1:]
+		}
+		return
+	}
+	s.pending = append(s.pending, heredocs(line)...)
+	// The bodies do not start until the end of the command line.
+	if len(line) > 0 && line[len(line)-1] == '\\' {
+		return
+	}
+	s.docs = append(s.docs[:0], s.pending...)
+	s.pending = s.pending[:0]
+}
+
+// heredocs returns the here-documents started on line.
+func heredocs(line []byte) []heredoc {
+	var docs []heredoc
+	var quote byte
+	arith := 0 // depth of parentheses within $((...)) or ((...))
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+			continue
+		case c == '\\':
+			i++
+			continue
+		case c == '\'' || c == '"':
+			quote = c
+			continue
+		case c == '#' && (i == 0 || isShellBlank(line[i-1])):
+			return docs
+		case arith > 0:
+			// << is a shift in arithmetic.
+			switch c {
+			case '(':
+				arith++
+			case ')':
+				arith--
+			}
+			continue
+		case c == '(' && i+1 < len(line) && line[i+1] == '(':
+			arith = 2
+			i++
+			continue
+		case c != '<' || i+1 >= len(line) || line[i+1] != '<':
+			continue
+		}
+		i += 2
+		if i < len(line) && line[i] == '<' {
+			// A <<< here-string.
+			continue
+		}
+		var doc heredoc
+		if i < len(line) && line[i] == '-' {
+			doc.strip = true
+			i++
+		}
+		for i < len(line) && isShellBlank(line[i]) {
+			i++
+		}
+		var word []byte
+	Word:
+		for ; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				} else {
+					word = append(word, c)
+				}
+			case c == '\'' || c == '"':
+				quote = c
+			case c == '\\' && i+1 < len(line):
+				i++
+				word = append(word, line[i])
+			case isShellBlank(c) || strings.IndexByte(";|&<>()", c) >= 0:
+				break Word
+			default:
+				word = append(word, c)
+			}
+		}
+		quote = 0
+		i--
+		if len(word) > 0 {
+			doc.word = string(word)
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+func isShellBlank(c byte) bool { return c == ' ' || c == '\t' }
@@ -0,0 +1,112 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestHeredocs(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		docs []heredoc
+	}{
+		{line: "echo hi"},
+		{line: "cat <<EOF", docs: []heredoc{{word: "EOF"}}},
+		{line: "cat << EOF > x", docs: []heredoc{{word: "EOF"}}},
+		{line: "cat <<-END", docs: []heredoc{{word: "END", strip: true}}},
+		{line: "cat <<'EOF'", docs: []heredoc{{word: "EOF"}}},
+		{line: `cat <<"E O F"`, docs: []heredoc{{word: "E O F"}}},
+		{line: `cat <<\EOF`, docs: []heredoc{{word: "EOF"}}},
+		{line: "cat <<A; cat <<-B", docs: []heredoc{{word: "A"}, {word: "B", strip: true}}},
+		{line: "cat <<<word"},
+		{line: "echo '<<EOF'"},
+		{line: `echo "<<EOF"`},
+		{line: "echo hi # <<EOF"},
+		{line: "echo a#<<EOF", docs: []heredoc{{word: "EOF"}}},
+		{line: "x=$((1 << 2))"},
+		{line: "((x <<= 1)); cat <<EOF", docs: []heredoc{{word: "EOF"}}},
+		{line: "echo $(( (1) << 2 )) <<EOF", docs: []heredoc{{word: "EOF"}}},
+	} {
+		if docs := heredocs([]byte(tt.line)); !reflect.DeepEqual(docs, tt.docs) {
+			t.Errorf("%q got %v, want %v", tt.line, docs, tt.docs)
+		}
+	}
+}
+
+func TestNewShell(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		in     string
+		out    string
+	}{
+		{
+			prefix: "  ",
+			in:     "if true; then\necho hi\nfi\n",
+			out:    "  if true; then\n  echo hi\n  fi\n",
+		}, {
+			prefix: "  ",
+			in:     "cat <<EOF\nbody\n  more\nEOF\necho done\n",
+			out:    "  cat <<EOF\nbody\n  more\nEOF\n  echo done\n",
+		}, {
+			prefix: "  ",
+			in:     "cat <<-EOF\n\tbody\n\tEOF\necho done\n",
+			out:    "  cat <<-EOF\n\tbody\n\tEOF\n  echo done\n",
+		}, {
+			prefix: "\t",
+			in:     "cat <<-EOF\n\tbody\n\tEOF\necho done\n",
+			out:    "\tcat <<-EOF\n\t\tbody\n\t\tEOF\n\techo done\n",
+		}, {
+			prefix: "\t",
+			in:     "cat <<EOF\nbody\nEOF\n",
+			out:    "\tcat <<EOF\nbody\nEOF\n",
+		}, {
+			prefix: "  ",
+			in:     "cat <<A; cat <<B\na\nA\nb\nB\nx\n",
+			out:    "  cat <<A; cat <<B\na\nA\nb\nB\n  x\n",
+		}, {
+			prefix: "  ",
+			in:     "cat <<EOF \\\n  | sort\nb\na\nEOF\n",
+			out:    "  cat <<EOF \\\n    | sort\nb\na\nEOF\n",
+		}, {
+			prefix: "  ",
+			in:     "cat <<EOF\nEOF is not alone\nEOF\n",
+			out:    "  cat <<EOF\nEOF is not alone\nEOF\n",
+		}, {
+			prefix: "  ",
+			in:     "x=$((1 << 2))\necho $x\n",
+			out:    "  x=$((1 << 2))\n  echo $x\n",
+		},
+	} {
+		var buf bytes.Buffer
+		if _, err := NewShell(&buf, tt.prefix).Write([]byte(tt.in)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q got %q, want %q", tt.in, got, tt.out)
+		}
+
+		buf.Reset()
+		w := NewShell(&buf, tt.prefix)
+		for i := range tt.in {
+			w.Write([]byte(tt.in[i : i+1]))
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q a byte at a time got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
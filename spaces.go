@@ -0,0 +1,57 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"io"
+	"strings"
+)
+
+// Prefixes of common widths are slices of these strings rather than being
+// built on each call.
+const (
+	spaces = "                                                                "
+	tabs   = "\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t\t"
+)
+
+// repeat returns n copies of the character in cache, which is a string of
+// copies of that character.
+func repeat(cache string, n int) string {
+	switch {
+	case n <= 0:
+		return ""
+	case n <= len(cache):
+		return cache[:n]
+	}
+	return strings.Repeat(cache[:1], n)
+}
+
+// Spaces returns a writer that indents the lines written to it by n spaces
+// and writes the results to w.  It is New(w, strings.Repeat(" ", n)) but does
+// not build the prefix for common widths.
+func Spaces(w io.Writer, n int) io.Writer {
+	return New(w, repeat(spaces, n))
+}
+
+// Tabs returns a writer that indents the lines written to it by n tabs and
+// writes the results to w.
+func Tabs(w io.Writer, n int) io.Writer {
+	return New(w, repeat(tabs, n))
+}
+
+// StringN returns s with each line indented by n spaces.
+func StringN(n int, s string) string {
+	return String(repeat(spaces, n), s)
+}
@@ -0,0 +1,48 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSpacesTabs(t *testing.T) {
+	for _, n := range []int{-1, 0, 1, 4, 64, 65, 100} {
+		want := ""
+		if n > 0 {
+			want = strings.Repeat(" ", n)
+		}
+		if got := StringN(n, "a\n"); got != want+"a\n" {
+			t.Errorf("StringN(%d) got %q", n, got)
+		}
+		var buf bytes.Buffer
+		io.WriteString(Spaces(&buf, n), "a\n")
+		if got := buf.String(); got != want+"a\n" {
+			t.Errorf("Spaces(%d) got %q", n, got)
+		}
+
+		if n > 0 {
+			want = strings.Repeat("\t", n)
+		}
+		buf.Reset()
+		io.WriteString(Tabs(&buf, n), "a\n")
+		if got := buf.String(); got != want+"a\n" {
+			t.Errorf("Tabs(%d) got %q", n, got)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+)
+
+// A State is a snapshot of a chain of writers returned by New.  It contains
+// only exported fields so it may be serialized, such as with encoding/json,
+// allowing a batch process to checkpoint its output part way through a
+// document and later resume producing correctly indented output.  Options,
+// other than those passed to Restore, are not part of the State.
+type State struct {
+	Prefixes    []string // prefix of each writer in the chain, outermost first
+	AtLineStart bool     // the next byte written starts a new line
+	Lines       int64    // number of newlines written by the chain
+}
+
+// Snapshot returns the State of the chain of writers ending with in.
+func (in *indenter) Snapshot() State {
+	var prefixes []string
+	for p := in; p != nil; p = p.p {
+		prefix := p.prefix
+		if p.p != nil {
+			// A truncated prefix need not extend the prefix of the
+			// writer it is nested in.
+			if bytes.HasPrefix(prefix, p.p.prefix) {
+				prefix = prefix[len(p.p.prefix):]
+			} else {
+				prefix = nil
+			}
+		}
+		prefixes = append(prefixes, string(prefix))
+	}
+	for i, j := 0, len(prefixes)-1; i < j; i, j = i+1, j-1 {
+		prefixes[i], prefixes[j] = prefixes[j], prefixes[i]
+	}
+	return State{
+		Prefixes:    prefixes,
+		AtLineStart: in.s.sol,
+		Lines:       in.s.lines,
+	}
+}
+
+// Restore returns a writer to w that continues from the State s, as returned
+// by the Snapshot method of a writer returned by New.  Restore recreates the
+// chain of writers, one for each prefix in s, and returns the innermost.  The
+// outer writers may be retrieved with Unwrap.  The opts are applied to the
+// innermost writer.  If s has no prefixes the returned writer does not indent.
+//
+// The Snapshot method is found with an interface assertion:
+//
+//	s := w.(interface{ Snapshot() indent.State }).Snapshot()
+func Restore(w io.Writer, s State, opts ...Option) io.Writer {
+	st := &state{w: w, sol: s.AtLineStart, lines: s.Lines}
+	in := &indenter{s: st}
+	for i, prefix := range s.Prefixes {
+		if i == 0 {
+			in.prefix = []byte(prefix)
+			continue
+		}
+		in = &indenter{
+			prefix: append(in.prefix[:len(in.prefix):len(in.prefix)], prefix...),
+			s:      st,
+			p:      in,
+		}
+	}
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
@@ -0,0 +1,93 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type snapshotter interface {
+	Snapshot() State
+}
+
+func TestSnapshot(t *testing.T) {
+	var buf bytes.Buffer
+	w1 := New(&buf, "1> ")
+	w2 := New(w1, "2> ")
+	io.WriteString(w1, "a\n")
+	io.WriteString(w2, "b\nc")
+
+	got := w2.(snapshotter).Snapshot()
+	want := State{
+		Prefixes:    []string{"1> ", "2> "},
+		AtLineStart: false,
+		Lines:       2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := w1.(snapshotter).Snapshot().Prefixes; !reflect.DeepEqual(got, []string{"1> "}) {
+		t.Errorf("outer writer got prefixes %q, want %q", got, []string{"1> "})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	// Write part of a document, checkpoint, and then finish it using
+	// a restored writer.  The output must match writing the whole
+	// document without interruption.
+	script := func(w1, w2 io.Writer, first bool) {
+		if first {
+			io.WriteString(w1, "a\n")
+			io.WriteString(w2, "b\nc")
+			return
+		}
+		io.WriteString(w2, "d\ne\n")
+		io.WriteString(w1, "f\n")
+	}
+
+	var whole bytes.Buffer
+	w1 := New(&whole, "1> ")
+	w2 := New(w1, "2> ")
+	script(w1, w2, true)
+	script(w1, w2, false)
+
+	var part bytes.Buffer
+	w1 = New(&part, "1> ")
+	w2 = New(w1, "2> ")
+	script(w1, w2, true)
+	data, err := json.Marshal(w2.(snapshotter).Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	w2 = Restore(&part, s)
+	w1 = Unwrap(w2, 1)
+	script(w1, w2, false)
+
+	if got, want := part.String(), whole.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := w2.(snapshotter).Snapshot().Lines, int64(5); got != want {
+		t.Errorf("got %d lines, want %d", got, want)
+	}
+}
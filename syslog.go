@@ -0,0 +1,90 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+)
+
+// A recordWriter writes each Write as a single record to a line oriented log
+// sink.
+type recordWriter struct {
+	w      io.Writer
+	marker []byte // added to continuation lines
+	escape bool   // escape newlines rather than marking continuation lines
+	out    outbuf
+	err    error
+}
+
+// NewContinuation returns a writer for line oriented log sinks, such as syslog
+// or journald, that treats each Write as a single log message.  The first
+// line of each message is written to w unchanged and the lines that follow,
+// the continuation lines, are prefixed with marker.  A newline at the end of a
+// message ends the message rather than starting a continuation line.  For
+// example, with a marker of "  | " the message "panic: oops\ngoroutine 1\n" is
+// written as
+//
+//	panic: oops
+//	  | goroutine 1
+//
+// This keeps a log parser from mistaking the lines of a multi-line message
+// for separate messages.  NewContinuation returns w if marker is the empty
+// string.
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewContinuation(w io.Writer, marker string) io.Writer {
+	if len(marker) == 0 {
+		return w
+	}
+	return &recordWriter{w: w, marker: []byte(marker)}
+}
+
+// NewEscapedNewlines returns a writer for line oriented log sinks that treats
+// each Write as a single log message, as NewContinuation does, but writes each
+// message on a single line by replacing each newline within the message with
+// the two characters \n.  A newline at the end of a message is written as is.
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewEscapedNewlines(w io.Writer) io.Writer {
+	return &recordWriter{w: w, escape: true}
+}
+
+func (r *recordWriter) Write(buf []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	for len(buf) > 0 {
+		x := bytes.IndexByte(buf, '\n')
+		if x < 0 || x == len(buf)-1 {
+			r.out.copy(buf)
+			break
+		}
+		if r.escape {
+			r.out.copy(buf[:x])
+			r.out.addString(`\n`)
+			r.out.drop(1)
+		} else {
+			r.out.copy(buf[:x+1])
+			r.out.add(r.marker)
+		}
+		buf = buf[x+1:]
+	}
+	n, err := r.out.writeTo(r.w)
+	r.err = err
+	return n, err
+}
@@ -0,0 +1,104 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestNewContinuation(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{in: []string{"a"}, out: "a"},
+		{in: []string{"a\n"}, out: "a\n"},
+		{in: []string{"a\nb\nc\n"}, out: "a\n  | b\n  | c\n"},
+		{in: []string{"a\nb", "c\nd\n"}, out: "a\n  | bc\n  | d\n"},
+		{in: []string{"a\n\n"}, out: "a\n  | \n"},
+	} {
+		var buf bytes.Buffer
+		w := NewContinuation(&buf, "  | ")
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: got %d, %v", s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+	var buf bytes.Buffer
+	if w := NewContinuation(&buf, ""); w != &buf {
+		t.Errorf("empty marker did not return the writer")
+	}
+}
+
+func TestNewEscapedNewlines(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{in: []string{"a"}, out: "a"},
+		{in: []string{"a\n"}, out: "a\n"},
+		{in: []string{"a\nb\nc\n"}, out: `a\nb\nc` + "\n"},
+		{in: []string{"a\nb", "c\n"}, out: `a\nbc` + "\n"},
+		{in: []string{"a\n\n"}, out: `a\n` + "\n"},
+	} {
+		var buf bytes.Buffer
+		w := NewEscapedNewlines(&buf)
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: got %d, %v", s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestRecordWriterShort(t *testing.T) {
+	for _, tt := range []struct {
+		escape bool
+		left   int
+		n      int
+	}{
+		{left: 2, n: 2},               // "a\n"
+		{left: 4, n: 2},               // "a\n  "
+		{left: 7, n: 3},               // "a\n  | b"
+		{escape: true, left: 1, n: 1}, // "a"
+		{escape: true, left: 2, n: 1}, // "a\"
+		{escape: true, left: 3, n: 2}, // "a\n"
+		{escape: true, left: 4, n: 3}, // "a\nb"
+	} {
+		fw := &fakeWriter{left: tt.left}
+		var w io.Writer
+		if tt.escape {
+			w = NewEscapedNewlines(fw)
+		} else {
+			w = NewContinuation(fw, "  | ")
+		}
+		n, err := io.WriteString(w, "a\nb\n")
+		if n != tt.n || err != io.EOF {
+			t.Errorf("escape %v, left %d: got %d, %v, want %d, %v", tt.escape, tt.left, n, err, tt.n, io.EOF)
+		}
+		if _, err := io.WriteString(w, "c"); err != io.EOF {
+			t.Errorf("escape %v, left %d: second write got %v, want %v", tt.escape, tt.left, err, io.EOF)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	testEventRE  = regexp.MustCompile(`^=== (RUN|CONT|PAUSE|NAME) +(\S+)$`)
+	testResultRE = regexp.MustCompile(`^ *--- (PASS|FAIL|SKIP): (\S+) \((.*)\)$`)
+)
+
+// A testNode is a test, or subtest, in the output of go test -v.
+type testNode struct {
+	name    string      // the last element of the test's path
+	depth   int         // the number of parent tests
+	result  string      // the result line, without the name
+	entries []testEntry // the log lines and subtests, in order
+}
+
+// A testEntry is either a log line or a subtest.
+type testEntry struct {
+	line string
+	test *testNode
+}
+
+// A testTree rewrites the output of go test -v.
+type testTree struct {
+	w       io.Writer
+	prefix  string
+	partial []byte
+	err     error
+	tests   map[string]*testNode // all the tests that have not finished
+	current *testNode            // the test log lines are added to
+	done    string               // the finished top level test to write
+}
+
+// NewTestTree returns a writer that rewrites the output of go test -v written
+// to it as an indented tree and writes the tree to w.  Each test is displayed
+// as its result, such as "PASS: TestName (0.01s)", followed by its log lines
+// and subtests, in the order they were written, indented by prefix.  Subtests
+// are displayed by the last element of their name.  The tree of a top level
+// test is written when the test finishes, so the output of parallel tests is
+// not interleaved.  Lines that are not part of a test, such as the final "ok"
+// line, are written as they are.
+//
+// Lines are processed when they are complete.  Close processes a final partial
+// line and writes the tests that have not finished, with a result of "RUN".
+// Close does not close w.
+func NewTestTree(w io.Writer, prefix string) io.WriteCloser {
+	return &testTree{
+		w:      w,
+		prefix: prefix,
+		tests:  map[string]*testNode{},
+	}
+}
+
+func (t *testTree) Write(buf []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	t.partial = append(t.partial, buf...)
+	for {
+		x := bytes.IndexByte(t.partial, '\n')
+		if x < 0 {
+			break
+		}
+		line := string(t.partial[:x])
+		t.partial = t.partial[x+1:]
+		if t.line(strings.TrimRight(line, "\r")); t.err != nil {
+			return 0, t.err
+		}
+	}
+	return len(buf), nil
+}
+
+// Close processes any partial line and writes the unfinished tests.
+func (t *testTree) Close() error {
+	if t.err != nil {
+		return t.err
+	}
+	if len(t.partial) > 0 {
+		t.line(string(t.partial))
+		t.partial = nil
+	}
+	if t.done != "" {
+		t.finish(t.done)
+		t.done = ""
+	}
+	var names []string
+	for name, n := range t.tests {
+		if n.depth == 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t.finish(name)
+	}
+	return t.err
+}
+
+// line processes a single line of output.
+func (t *testTree) line(line string) {
+	m := testResultRE.FindStringSubmatch(line)
+	if t.done != "" && !(m != nil && strings.HasPrefix(m[2], t.done+"/")) && !strings.HasPrefix(line, " ") {
+		// The results of the subtests of a test, and any of its log
+		// lines, follow its own result.
+		t.finish(t.done)
+		t.done = ""
+		t.current = nil
+	}
+	if m != nil {
+		n := t.node(m[2])
+		n.result = m[1] + ": " + n.name + " (" + m[3] + ")"
+		// Older versions of go test write the log lines after the result.
+		t.current = n
+		if n.depth == 0 {
+			t.done = m[2]
+		}
+		return
+	}
+	if m := testEventRE.FindStringSubmatch(line); m != nil {
+		if m[1] == "RUN" {
+			t.current = t.node(m[2])
+		} else {
+			t.current = t.tests[m[2]]
+		}
+		return
+	}
+	if t.current == nil {
+		_, t.err = io.WriteString(t.w, line+"\n")
+		return
+	}
+	// Log lines are indented by four spaces for each level of the test.
+	n := 4 * (t.current.depth + 1)
+	if ws := len(line) - len(strings.TrimLeft(line, " ")); ws < n {
+		n = ws
+	}
+	t.current.entries = append(t.current.entries, testEntry{line: line[n:]})
+}
+
+// node returns the node of the test named name, adding it and its parents if
+// needed.
+func (t *testTree) node(name string) *testNode {
+	if n := t.tests[name]; n != nil {
+		return n
+	}
+	n := &testNode{name: name}
+	if x := strings.LastIndexByte(name, '/'); x >= 0 {
+		parent := t.node(name[:x])
+		n.name = name[x+1:]
+		n.depth = parent.depth + 1
+		parent.entries = append(parent.entries, testEntry{test: n})
+	}
+	t.tests[name] = n
+	return n
+}
+
+// finish writes the tree of the top level test named name and forgets it and
+// its subtests.
+func (t *testTree) finish(name string) {
+	n := t.tests[name]
+	for k := range t.tests {
+		if k == name || strings.HasPrefix(k, name+"/") {
+			delete(t.tests, k)
+		}
+	}
+	var b strings.Builder
+	n.write(&b, t.prefix)
+	_, t.err = io.WriteString(t.w, b.String())
+}
+
+// write writes the tree of n to w.
+func (n *testNode) write(w io.Writer, prefix string) {
+	result := n.result
+	if result == "" {
+		result = "RUN: " + n.name
+	}
+	io.WriteString(w, result+"\n")
+	w = New(w, prefix)
+	for _, e := range n.entries {
+		if e.test != nil {
+			e.test.write(w, prefix)
+			continue
+		}
+		io.WriteString(w, e.line+"\n")
+	}
+}
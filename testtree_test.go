@@ -0,0 +1,119 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestNewTestTree(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "simple",
+			in: `=== RUN   TestA
+    a_test.go:10: hello
+--- PASS: TestA (0.00s)
+PASS
+ok  	example.com/a	0.01s
+`,
+			out: `PASS: TestA (0.00s)
+  a_test.go:10: hello
+PASS
+ok  	example.com/a	0.01s
+`,
+		},
+		{
+			name: "subtests",
+			in: `=== RUN   TestA
+=== RUN   TestA/one
+        a_test.go:12: in one
+            continued
+=== RUN   TestA/two
+=== RUN   TestA/two/deep
+--- FAIL: TestA (0.00s)
+    --- PASS: TestA/one (0.00s)
+    --- FAIL: TestA/two (0.00s)
+        --- FAIL: TestA/two/deep (0.00s)
+FAIL
+`,
+			out: `FAIL: TestA (0.00s)
+  PASS: one (0.00s)
+    a_test.go:12: in one
+        continued
+  FAIL: two (0.00s)
+    FAIL: deep (0.00s)
+FAIL
+`,
+		},
+		{
+			name: "parallel",
+			in: `=== RUN   TestA
+=== PAUSE TestA
+=== RUN   TestB
+=== PAUSE TestB
+=== CONT  TestA
+    a_test.go:1: a log
+=== CONT  TestB
+    b_test.go:1: b log
+--- PASS: TestB (0.00s)
+=== CONT  TestA
+    a_test.go:2: a again
+--- PASS: TestA (0.00s)
+`,
+			out: `PASS: TestB (0.00s)
+  b_test.go:1: b log
+PASS: TestA (0.00s)
+  a_test.go:1: a log
+  a_test.go:2: a again
+`,
+		},
+		{
+			name: "unfinished",
+			in:   "=== RUN   TestA\n    a_test.go:1: partial",
+			out:  "RUN: TestA\n  a_test.go:1: partial\n",
+		},
+	} {
+		var buf bytes.Buffer
+		w := NewTestTree(&buf, "  ")
+		// Write a byte at a time to test reassembling lines.
+		for i := 0; i < len(tt.in); i++ {
+			if n, err := w.Write([]byte{tt.in[i]}); n != 1 || err != nil {
+				t.Fatalf("%s: Write got %d, %v", tt.name, n, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%s: Close: %v", tt.name, err)
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.name, got, tt.out)
+		}
+	}
+}
+
+func TestNewTestTreeError(t *testing.T) {
+	w := NewTestTree(&fakeWriter{}, "  ")
+	if _, err := io.WriteString(w, "PASS\n"); err != io.EOF {
+		t.Errorf("got %v, want %v", err, io.EOF)
+	}
+	if _, err := io.WriteString(w, "PASS\n"); err != io.EOF {
+		t.Errorf("second write got %v, want %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,185 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// A Heading is a heading found in a Markdown document.
+type Heading struct {
+	Level  int    // 1 through 6
+	Text   string // the text of the heading
+	Line   int    // the line number of the heading (1 based)
+	Anchor string // the anchor GitHub generates for the heading
+}
+
+// Headings returns the ATX (# Heading) and setext (underlined) headings found
+// in markdown, in the order they appear.  Lines in code blocks are not
+// headings.  Each heading is given the anchor GitHub generates for it, with
+// duplicate anchors numbered as GitHub does.
+func Headings(markdown string) []Heading {
+	var headings []Heading
+	anchors := map[string]int{}
+	add := func(level int, text string, line int) {
+		anchor := anchorOf(text)
+		if n := anchors[anchor]; n > 0 {
+			anchors[anchor]++
+			anchor = fmt.Sprintf("%s-%d", anchor, n)
+		} else {
+			anchors[anchor] = 1
+		}
+		headings = append(headings, Heading{Level: level, Text: text, Line: line, Anchor: anchor})
+	}
+
+	lines := strings.Split(strings.Replace(markdown, "\r\n", "\n", -1), "\n")
+	para := "" // the text of the paragraph line before this one
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if f, ok := openFence(line); ok {
+			for i++; i < len(lines) && !f.closes(lines[i]); i++ {
+			}
+			para = ""
+			continue
+		}
+		switch {
+		case isBlank(line):
+			para = ""
+		case para == "" && (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")):
+			// An indented code block.
+		case para != "" && isSetext(line, '='):
+			add(1, para, i)
+			para = ""
+		case para != "" && isSetext(line, '-'):
+			add(2, para, i)
+			para = ""
+		default:
+			if level, text, ok := atxHeading(line); ok {
+				add(level, text, i+1)
+				para = ""
+				continue
+			}
+			if isListItem(line) {
+				para = ""
+				continue
+			}
+			para = strings.TrimSpace(line)
+		}
+	}
+	return headings
+}
+
+// atxHeading returns the level and text of line if it is an ATX heading.
+func atxHeading(line string) (int, string, bool) {
+	s := strings.TrimLeft(line, " ")
+	if len(line)-len(s) > 3 {
+		return 0, "", false
+	}
+	level := len(s) - len(strings.TrimLeft(s, "#"))
+	if level < 1 || level > 6 {
+		return 0, "", false
+	}
+	s = s[level:]
+	if s != "" && s[0] != ' ' && s[0] != '\t' {
+		return 0, "", false
+	}
+	s = strings.TrimSpace(s)
+	// Remove an optional closing sequence of #s.
+	if t := strings.TrimRight(s, "#"); t == "" || strings.HasSuffix(t, " ") || strings.HasSuffix(t, "\t") {
+		s = strings.TrimSpace(t)
+	}
+	return level, s, true
+}
+
+// isSetext returns true if line underlines a setext heading with c.
+func isSetext(line string, c byte) bool {
+	s := strings.TrimLeft(line, " ")
+	if len(line)-len(s) > 3 {
+		return false
+	}
+	s = strings.TrimRight(s, " \t")
+	return s != "" && strings.Trim(s, string(c)) == ""
+}
+
+// anchorOf returns the anchor GitHub generates for a heading with text: the
+// text is lower cased, spaces become hyphens, and all punctuation other than
+// hyphens and underscores is removed.
+func anchorOf(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// TableOfContents returns a Markdown list of the headings in markdown, with
+// each heading nested beneath the heading it follows that has a lower level.
+// The least significant level found is not indented.  Each level of nesting
+// is indented by indent, which defaults to two spaces for bulleted lists and
+// three spaces for numbered lists.  If numbered is true the list is numbered,
+// otherwise it is bulleted with "-".  If linked is true each entry is a link
+// to the heading's anchor.
+func TableOfContents(markdown, indent string, numbered, linked bool) string {
+	headings := Headings(markdown)
+	if len(headings) == 0 {
+		return ""
+	}
+	if indent == "" {
+		indent = "  "
+		if numbered {
+			indent = "   "
+		}
+	}
+	min := headings[0].Level
+	for _, h := range headings {
+		if h.Level < min {
+			min = h.Level
+		}
+	}
+
+	var b strings.Builder
+	writers := []io.Writer{&b}
+	var counts []int
+	for _, h := range headings {
+		depth := h.Level - min
+		for len(writers) <= depth {
+			writers = append(writers, New(writers[len(writers)-1], indent))
+		}
+		if len(counts) <= depth {
+			counts = append(counts, make([]int, depth+1-len(counts))...)
+		}
+		counts = counts[:depth+1]
+		counts[depth]++
+
+		text := h.Text
+		if linked {
+			text = "[" + text + "](#" + h.Anchor + ")"
+		}
+		if numbered {
+			fmt.Fprintf(writers[depth], "%d. %s\n", counts[depth], text)
+		} else {
+			fmt.Fprintf(writers[depth], "- %s\n", text)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"reflect"
+	"testing"
+)
+
+const tocDoc = `# Title
+
+Intro text.
+
+## Getting Started
+
+` + "```" + `
+# not a heading
+` + "```" + `
+
+    # also not a heading
+
+### Install (Linux)
+#### Deep ####
+## Usage
+Setext Heading
+--------------
+## Usage
+#not a heading
+`
+
+func TestHeadings(t *testing.T) {
+	want := []Heading{
+		{Level: 1, Text: "Title", Line: 1, Anchor: "title"},
+		{Level: 2, Text: "Getting Started", Line: 5, Anchor: "getting-started"},
+		{Level: 3, Text: "Install (Linux)", Line: 13, Anchor: "install-linux"},
+		{Level: 4, Text: "Deep", Line: 14, Anchor: "deep"},
+		{Level: 2, Text: "Usage", Line: 15, Anchor: "usage"},
+		{Level: 2, Text: "Setext Heading", Line: 16, Anchor: "setext-heading"},
+		{Level: 2, Text: "Usage", Line: 18, Anchor: "usage-1"},
+	}
+	if got := Headings(tocDoc); !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestTableOfContents(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		in       string
+		indent   string
+		numbered bool
+		linked   bool
+		out      string
+	}{{
+		name: "empty",
+		in:   "no headings",
+	}, {
+		name: "bullets",
+		in:   tocDoc,
+		out: "" +
+			"- Title\n" +
+			"  - Getting Started\n" +
+			"    - Install (Linux)\n" +
+			"      - Deep\n" +
+			"  - Usage\n" +
+			"  - Setext Heading\n" +
+			"  - Usage\n",
+	}, {
+		name:     "numbered and linked",
+		in:       "## A\n### B\n### C\n## D\n### E\n",
+		numbered: true,
+		linked:   true,
+		out: "" +
+			"1. [A](#a)\n" +
+			"   1. [B](#b)\n" +
+			"   2. [C](#c)\n" +
+			"2. [D](#d)\n" +
+			"   1. [E](#e)\n",
+	}, {
+		name:   "skipped level",
+		in:     "# A\n### B\n# C\n",
+		indent: "\t",
+		out:    "- A\n\t\t- B\n- C\n",
+	}} {
+		if got := TableOfContents(tt.in, tt.indent, tt.numbered, tt.linked); got != tt.out {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.name, got, tt.out)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A TokenKind is the kind of a Token.
+type TokenKind int
+
+const (
+	// LineToken is a non-blank line of text.
+	LineToken TokenKind = iota
+	// IndentToken starts a block indented more than the line before it.
+	IndentToken
+	// DedentToken ends a block.
+	DedentToken
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case LineToken:
+		return "LINE"
+	case IndentToken:
+		return "INDENT"
+	case DedentToken:
+		return "DEDENT"
+	}
+	return fmt.Sprintf("TokenKind(%d)", int(k))
+}
+
+// A Token is a token returned by a Tokenizer.
+type Token struct {
+	Kind   TokenKind
+	Text   string // the text of a LineToken without its indentation or newline
+	Indent string // the indentation of a LineToken, or of the block started or returned to
+	LineNo int    // the line number, starting at 1, of the line that produced the token
+}
+
+// A Tokenizer reads text structured by indentation, such as Python source or
+// a configuration language, and returns it as a stream of LineToken, IndentToken, and
+// DedentToken tokens, as the Python tokenizer does.  A line indented more than the
+// line before it is preceded by an IndentToken.  A line indented less is
+// preceded by a DedentToken for each block it ends and must have the same indentation as
+// the block it returns to.  Blank lines are ignored.  At the end of the input
+// a DedentToken is returned for each block that is still open.
+//
+// Indentation is compared as strings rather than as columns, so a block must
+// start with the indentation of the block it is nested in.  This rejects
+// ambiguous mixes of tabs and spaces.
+type Tokenizer struct {
+	r      *bufio.Reader
+	stack  []string // the indentation of the open blocks
+	queue  []Token  // tokens waiting to be returned
+	lineNo int
+	err    error
+}
+
+// NewTokenizer returns a Tokenizer that reads from r.
+func NewTokenizer(r io.Reader) *Tokenizer {
+	return &Tokenizer{
+		r:     bufio.NewReader(r),
+		stack: []string{""},
+	}
+}
+
+// Next returns the next token.  It returns io.EOF after the last token.  An
+// inconsistently indented line returns an error that includes its line number.
+// Once Next returns an error it always returns that error.
+func (t *Tokenizer) Next() (Token, error) {
+	for len(t.queue) == 0 {
+		if t.err != nil {
+			return Token{}, t.err
+		}
+		t.read()
+	}
+	tok := t.queue[0]
+	t.queue = t.queue[1:]
+	return tok, nil
+}
+
+// read reads the next non-blank line and queues its tokens.
+func (t *Tokenizer) read() {
+	var line string
+	for {
+		var err error
+		line, err = t.r.ReadString('\n')
+		if line == "" && err != nil {
+			t.err = err
+			// Close the open blocks.
+			for len(t.stack) > 1 {
+				t.stack = t.stack[:len(t.stack)-1]
+				t.queue = append(t.queue, Token{Kind: DedentToken, Indent: t.stack[len(t.stack)-1], LineNo: t.lineNo})
+			}
+			return
+		}
+		t.lineNo++
+		if !isBlank(line) {
+			break
+		}
+	}
+	line = strings.TrimRight(line, "\r\n")
+	ws := leading(line)
+	tok := Token{Kind: LineToken, Text: line[len(ws):], Indent: ws, LineNo: t.lineNo}
+	switch cur := t.stack[len(t.stack)-1]; {
+	case ws == cur:
+	case strings.HasPrefix(ws, cur):
+		t.stack = append(t.stack, ws)
+		t.queue = append(t.queue, Token{Kind: IndentToken, Indent: ws, LineNo: t.lineNo})
+	default:
+		n := len(t.stack) - 1
+		for n > 0 && len(t.stack[n]) > len(ws) {
+			n--
+		}
+		if t.stack[n] != ws {
+			t.err = fmt.Errorf("indent: line %d: indentation %q does not match any outer block", t.lineNo, ws)
+			return
+		}
+		for len(t.stack)-1 > n {
+			t.stack = t.stack[:len(t.stack)-1]
+			t.queue = append(t.queue, Token{Kind: DedentToken, Indent: t.stack[len(t.stack)-1], LineNo: t.lineNo})
+		}
+	}
+	t.queue = append(t.queue, tok)
+}
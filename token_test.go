@@ -0,0 +1,93 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// tokens returns the tokens of in in a compact form along with the final
+// error.
+func tokens(in string) (string, error) {
+	t := NewTokenizer(strings.NewReader(in))
+	var out []string
+	for {
+		tok, err := t.Next()
+		if err != nil {
+			return strings.Join(out, " "), err
+		}
+		switch tok.Kind {
+		case LineToken:
+			out = append(out, tok.Text)
+		default:
+			out = append(out, tok.Kind.String())
+		}
+	}
+}
+
+func TestTokenizer(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+		err     string
+	}{
+		{in: "", out: ""},
+		{in: "a\nb\n", out: "a b"},
+		{in: "a\n  b\n  c\nd\n", out: "a INDENT b c DEDENT d"},
+		{in: "a\n  b\n    c\n\n  d\n", out: "a INDENT b INDENT c DEDENT d DEDENT"},
+		{in: "a:\n\tb:\n\t\tc\ne", out: "a: INDENT b: INDENT c DEDENT DEDENT e"},
+		{in: "  a\r\n\n \t \n  b", out: "INDENT a b DEDENT"},
+		{in: "a\n    b\n  c\n", out: "a INDENT b", err: `indent: line 3: indentation "  " does not match any outer block`},
+		{in: "a\n  b\n\tc\n", out: "a INDENT b", err: `indent: line 3: indentation "\t" does not match any outer block`},
+	} {
+		out, err := tokens(tt.in)
+		if out != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, out, tt.out)
+		}
+		switch {
+		case tt.err == "" && err != io.EOF:
+			t.Errorf("%q: got error %v, want %v", tt.in, err, io.EOF)
+		case tt.err != "" && (err == nil || err.Error() != tt.err):
+			t.Errorf("%q: got error %v, want %s", tt.in, err, tt.err)
+		}
+	}
+}
+
+func TestTokenizerPositions(t *testing.T) {
+	tz := NewTokenizer(strings.NewReader("a\n\n  b\n"))
+	var got []Token
+	for {
+		tok, err := tz.Next()
+		if err != nil {
+			break
+		}
+		got = append(got, tok)
+	}
+	want := []Token{
+		{Kind: LineToken, Text: "a", LineNo: 1},
+		{Kind: IndentToken, Indent: "  ", LineNo: 3},
+		{Kind: LineToken, Text: "b", Indent: "  ", LineNo: 3},
+		{Kind: DedentToken, LineNo: 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("token %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
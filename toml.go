@@ -0,0 +1,206 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "io"
+
+// The states of a tomlScanner.
+const (
+	tomlNormal = iota
+	tomlComment
+	tomlBasic     // "..."
+	tomlLiteral   // '...'
+	tomlMLBasic   // """..."""
+	tomlMLLiteral // '''...'''
+)
+
+// A tomlScanner tracks just enough of the TOML syntax, one byte at a time, to
+// know if we are in a multi-line string.
+type tomlScanner struct {
+	state int
+	esc   bool // the previous byte was a backslash
+	q     int  // the number of consecutive quotes seen
+	qc    byte // the quote character being counted in tomlNormal
+}
+
+// inMultiLine returns true if s is in a multi-line string.
+func (s *tomlScanner) inMultiLine() bool {
+	return s.state == tomlMLBasic || s.state == tomlMLLiteral
+}
+
+func (s *tomlScanner) step(c byte) {
+	switch s.state {
+	case tomlNormal:
+		if s.q > 0 {
+			if c == s.qc {
+				if s.q++; s.q == 3 {
+					s.q = 0
+					s.state = tomlMLBasic
+					if c == '\'' {
+						s.state = tomlMLLiteral
+					}
+				}
+				return
+			}
+			// A single quote opened a string, two quotes
+			// were an empty string.
+			q := s.q
+			s.q = 0
+			if q == 1 {
+				s.state = tomlBasic
+				if s.qc == '\'' {
+					s.state = tomlLiteral
+				}
+				s.step(c)
+				return
+			}
+		}
+		switch c {
+		case '#':
+			s.state = tomlComment
+		case '"', '\'':
+			s.q = 1
+			s.qc = c
+		}
+	case tomlComment:
+		if c == '\n' {
+			s.state = tomlNormal
+		}
+	case tomlBasic:
+		switch {
+		case s.esc:
+			s.esc = false
+		case c == '\\':
+			s.esc = true
+		case c == '"', c == '\n':
+			// A newline is not valid in a basic string.
+			s.state = tomlNormal
+		}
+	case tomlLiteral:
+		if c == '\'' || c == '\n' {
+			s.state = tomlNormal
+		}
+	case tomlMLBasic, tomlMLLiteral:
+		qc := byte('"')
+		if s.state == tomlMLLiteral {
+			qc = '\''
+		}
+		if s.esc {
+			s.esc = false
+			return
+		}
+		if c == qc {
+			s.q++
+			return
+		}
+		// Up to two quotes may directly precede the closing
+		// delimiter so we only know the string is closed once we
+		// see something other than a quote.
+		if s.q >= 3 {
+			s.q = 0
+			s.state = tomlNormal
+			s.step(c)
+			return
+		}
+		s.q = 0
+		if c == '\\' && s.state == tomlMLBasic {
+			s.esc = true
+		}
+	}
+}
+
+// A tomlWriter indents or dedents TOML text.
+type tomlWriter struct {
+	w      io.Writer
+	prefix []byte
+	dedent bool
+	out    outbuf
+	err    error
+	s      tomlScanner
+	sol    bool
+	m      int // number of bytes of prefix matched when dedenting
+}
+
+// NewTOML returns a writer that prefixes each line of the TOML document written
+// to it with prefix and writes the results to w.  Lines that start within a
+// multi-line basic or literal string are part of the string and are left
+// untouched.  NewTOML returns w if prefix is the empty string.
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewTOML(w io.Writer, prefix string) io.Writer {
+	if len(prefix) == 0 {
+		return w
+	}
+	return &tomlWriter{
+		w:      w,
+		prefix: []byte(prefix),
+		sol:    true,
+	}
+}
+
+// NewTOMLDedent is the inverse of NewTOML.  It returns a writer that removes
+// prefix from the start of each line of the TOML document written to it and
+// writes the results to w.  Lines that do not start with prefix and lines that
+// start within a multi-line string are left untouched.  NewTOMLDedent returns w
+// if prefix is the empty string.
+func NewTOMLDedent(w io.Writer, prefix string) io.Writer {
+	if len(prefix) == 0 {
+		return w
+	}
+	return &tomlWriter{
+		w:      w,
+		prefix: []byte(prefix),
+		dedent: true,
+		sol:    true,
+	}
+}
+
+func (t *tomlWriter) Write(buf []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	for _, c := range buf {
+		if t.sol {
+			switch {
+			case t.s.inMultiLine():
+				t.sol = false
+			case !t.dedent:
+				t.out.add(t.prefix)
+				t.sol = false
+			case c == t.prefix[t.m]:
+				// Hold on to what might be the prefix.
+				t.out.drop(1)
+				t.s.step(c)
+				if t.m++; t.m == len(t.prefix) {
+					t.m = 0
+					t.sol = false
+				}
+				continue
+			default:
+				// It was not the prefix after all.
+				t.out.add(t.prefix[:t.m])
+				t.m = 0
+				t.sol = false
+			}
+		}
+		t.out.copyByte(c)
+		t.s.step(c)
+		t.sol = c == '\n'
+	}
+	n, err := t.out.writeTo(t.w)
+	t.err = err
+	return n, err
+}
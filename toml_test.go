@@ -0,0 +1,102 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"testing"
+)
+
+var tomlTests = []struct {
+	in  string
+	out string
+}{
+	{},
+	{
+		in:  "a = 1\nb = \"two\"\n",
+		out: "  a = 1\n  b = \"two\"\n",
+	}, {
+		in:  "s = \"\"\"\nline 1\n  line 2\n\"\"\"\nx = 1\n",
+		out: "  s = \"\"\"\nline 1\n  line 2\n\"\"\"\n  x = 1\n",
+	}, {
+		in:  "s = '''\nline 1\n'''\nx = 1\n",
+		out: "  s = '''\nline 1\n'''\n  x = 1\n",
+	}, {
+		// Quotes at the end of a multi-line string.
+		in:  "s = \"\"\"\nsay \"hi\"\"\"\"\nx = 1\n",
+		out: "  s = \"\"\"\nsay \"hi\"\"\"\"\n  x = 1\n",
+	}, {
+		// Escaped quotes do not end the string.
+		in:  "s = \"\"\"\n\\\"\"\"\nstill\"\"\"\nx = 1\n",
+		out: "  s = \"\"\"\n\\\"\"\"\nstill\"\"\"\n  x = 1\n",
+	}, {
+		// Things that look like multi-line strings.
+		in:  "e = \"\"\nc = 1 # \"\"\"\ns = \"'''\"\nl = '\"\"\"'\nx = 1\n",
+		out: "  e = \"\"\n  c = 1 # \"\"\"\n  s = \"'''\"\n  l = '\"\"\"'\n  x = 1\n",
+	}, {
+		in:  "[table]\ns = \"\"\"one line\"\"\"\nx = 1",
+		out: "  [table]\n  s = \"\"\"one line\"\"\"\n  x = 1",
+	},
+}
+
+func TestNewTOML(t *testing.T) {
+	for _, tt := range tomlTests {
+		var buf bytes.Buffer
+		if _, err := NewTOML(&buf, "  ").Write([]byte(tt.in)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q got %q, want %q", tt.in, got, tt.out)
+		}
+
+		// The same thing a byte at a time.
+		buf.Reset()
+		w := NewTOML(&buf, "  ")
+		for i := range tt.in {
+			w.Write([]byte(tt.in[i : i+1]))
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q a byte at a time got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestNewTOMLDedent(t *testing.T) {
+	for _, tt := range tomlTests {
+		var buf bytes.Buffer
+		if _, err := NewTOMLDedent(&buf, "  ").Write([]byte(tt.out)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.in {
+			t.Errorf("%q got %q, want %q", tt.out, got, tt.in)
+		}
+
+		buf.Reset()
+		w := NewTOMLDedent(&buf, "  ")
+		for i := range tt.out {
+			w.Write([]byte(tt.out[i : i+1]))
+		}
+		if got := buf.String(); got != tt.in {
+			t.Errorf("%q a byte at a time got %q, want %q", tt.out, got, tt.in)
+		}
+	}
+
+	// Lines that do not start with the prefix are unchanged.
+	var buf bytes.Buffer
+	NewTOMLDedent(&buf, "    ").Write([]byte("    a = 1\n  b = 2\nc = 3\n"))
+	if got, want := buf.String(), "a = 1\n  b = 2\nc = 3\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
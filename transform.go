@@ -0,0 +1,715 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ErrLineTooLong is returned by a writer using WithMaxLineLen with the Fail
+// action when a line would be longer than the maximum.
+var ErrLineTooLong = errors.New("indent: line too long")
+
+// An Action is what WithMaxLineLen does with a line that is too long, or what
+// WithMaxDepth and WithMaxPrefixLen do with a writer that exceeds their limit.
+type Action int
+
+const (
+	// Wrap breaks the line so the rest of it starts a new, prefixed, line.
+	Wrap Action = iota
+	// Truncate discards the rest of the line.
+	Truncate
+	// Fail writes the line up to the maximum and returns ErrLineTooLong.
+	Fail
+)
+
+// WithDropCR causes the chain of writers to remove all carriage returns from
+// what is written to them.  This keeps the output of tools that end lines with
+// "\r\n", or that write a bare "\r", from leaving carriage returns within the
+// indented lines.
+func WithDropCR() Option {
+	return func(in *indenter) {
+		in.s.opts().dropCR = true
+		in.s.slow = true
+	}
+}
+
+// WithMaxLineLen causes the chain of writers to limit the lines they write to
+// n bytes, including the prefix and postfix but not the newline.  Lines that
+// would be longer are handled as specified by onExceed.  Lines are only broken
+// at the start of a UTF-8 encoded rune.  This is used when the destination,
+// such as SMTP or a log collector, has a hard limit on the length of a line.
+// A limit of 0 or less is no limit.
+func WithMaxLineLen(n int, onExceed Action) Option {
+	return func(in *indenter) {
+		o := in.s.opts()
+		o.maxLine = n
+		o.onExceed = onExceed
+		in.s.slow = n > 0 || in.s.slow
+	}
+}
+
+// WithStripANSI causes the chain of writers to remove ANSI escape sequences,
+// such as those that color text, from what is written to them.  This is used
+// when writing the captured output of a tool to a plain log file.  Escape
+// sequences in the prefixes are not removed.
+func WithStripANSI() Option {
+	return func(in *indenter) {
+		in.s.opts().stripANSI = true
+		in.s.slow = true
+	}
+}
+
+// WithVisibleWhitespace causes the chain of writers to show the leading
+// whitespace of each line written to them, as editors do when showing
+// whitespace.  Each leading tab is replaced by » and each leading space by ·.
+// This helps diagnose lines indented with a mix of tabs and spaces.  The
+// whitespace in prefixes is not shown.
+func WithVisibleWhitespace() Option {
+	return func(in *indenter) {
+		in.s.opts().visible = true
+		in.s.slow = true
+	}
+}
+
+// WithPrefixAfterIndent causes the chain of writers to add their prefixes
+// after the leading whitespace of each line rather than at the start of the
+// line.  This preserves the indentation of text that is already indented,
+// such as when commenting out code: with a prefix of "// " the line "\tfoo"
+// becomes "\t// foo".  A line with only whitespace has the prefix added at
+// its end.
+func WithPrefixAfterIndent() Option {
+	return func(in *indenter) {
+		in.s.opts().afterIndent = true
+		in.s.slow = true
+	}
+}
+
+// WithCommentColumn causes the chain of writers to align the first marker,
+// such as "//" or "#", on each line written to them so it starts at the
+// display column column, counting from 0 at the start of the prefix.  The
+// whitespace before the marker is replaced with spaces.  Tabs and wide runes
+// before it are measured as they are displayed on a terminal.  A marker that
+// cannot start at column is separated from the text before it by one space.
+// The marker is found as text is written, so whitespace at the end of a Write
+// is held until the next Write, Flush, or end of line.
+func WithCommentColumn(marker string, column int) Option {
+	return func(in *indenter) {
+		o := in.s.opts()
+		o.marker = []byte(marker)
+		o.commentCol = column
+		in.s.slow = len(marker) > 0 || in.s.slow
+	}
+}
+
+// WithFormFeed causes the chain of writers to treat a form feed as the
+// separator between pages of output, as in a printed report.  The form feed
+// is replaced by divider, which is not prefixed and has a newline added if it
+// does not end in one.  A line interrupted by a form feed is ended first and
+// the next line starts with its prefix.  The count of lines reported by
+// Snapshot restarts at 0 at the start of each page.  An empty divider just
+// removes the form feed.
+func WithFormFeed(divider string) Option {
+	return func(in *indenter) {
+		if divider != "" && divider[len(divider)-1] != '\n' {
+			divider += "\n"
+		}
+		o := in.s.opts()
+		o.divider = []byte(divider)
+		o.formFeed = true
+		in.s.slow = true
+	}
+}
+
+// WithMaxLines causes the chain of writers to stop writing lines after n
+// lines have been written.  The lines that follow are counted and, when the
+// outermost writer of the chain is closed, replaced by a single line such as
+// "… (1,234 more lines)" with the prefix of the outermost writer.  This
+// gracefully truncates unbounded output, such as that of a subprocess, that is
+// embedded in a report.  WithKeepLast causes the last lines to be written
+// after the marker.  A limit of 0 or less is no limit.
+func WithMaxLines(n int) Option {
+	return func(in *indenter) {
+		in.s.opts().maxLines = n
+		in.s.slow = n > 0 || in.s.slow
+	}
+}
+
+// WithMaxBytes is like WithMaxLines but stops writing lines once n bytes of
+// output have been written.  The line that reaches the limit is completed, so
+// the output may exceed n by up to a line.
+func WithMaxBytes(n int64) Option {
+	return func(in *indenter) {
+		in.s.opts().maxBytes = n
+		in.s.slow = n > 0 || in.s.slow
+	}
+}
+
+// WithKeepLast causes the chain of writers to write the last k lines that
+// were not written due to WithMaxLines or WithMaxBytes after the marker that
+// replaces them.
+func WithKeepLast(k int) Option {
+	return func(in *indenter) {
+		in.s.opts().keep = k
+	}
+}
+
+// overLimit reports whether a new line would exceed the limits set by
+// WithMaxLines or WithMaxBytes when lines lines and n bytes of output are
+// waiting to be written.
+func (in *indenter) overLimit(lines, n int) bool {
+	return in.s.opt.maxLines > 0 && in.s.lines+int64(lines) >= int64(in.s.opt.maxLines) ||
+		in.s.opt.maxBytes > 0 && in.s.stats.Bytes+int64(n) >= in.s.opt.maxBytes
+}
+
+// elide consumes the first line of buf, which is being elided, and returns
+// the rest of buf.  The line, as it would have been written, is kept if
+// needed by WithKeepLast.
+func (in *indenter) elide(o *outbuf, buf, prefix []byte, sol bool) []byte {
+	line := buf
+	if x := bytes.IndexByte(buf, '\n'); x >= 0 {
+		line = buf[:x+1]
+	}
+	o.drop(len(line))
+	if sol {
+		in.s.opt.elided++
+		if in.s.opt.keep > 0 {
+			if len(in.s.opt.tail) == in.s.opt.keep {
+				in.s.opt.tail = append(in.s.opt.tail[:0], in.s.opt.tail[1:]...)
+			}
+			in.s.opt.tail = append(in.s.opt.tail, nil)
+		}
+	}
+	if in.s.opt.keep > 0 {
+		var t outbuf
+		if sol {
+			t.add(prefix)
+		}
+		in.transformLine(&t, line)
+		last := &in.s.opt.tail[len(in.s.opt.tail)-1]
+		*last = append(*last, t.buf...)
+	}
+	return buf[len(line):]
+}
+
+// writeElided writes the marker that replaces the lines that were elided
+// followed by the lines kept by WithKeepLast.
+func (in *indenter) writeElided() error {
+	if in.s.opt.elided == 0 {
+		return nil
+	}
+	more := in.s.opt.elided - int64(len(in.s.opt.tail))
+	var out []byte
+	if more > 0 {
+		lines := "lines"
+		if more == 1 {
+			lines = "line"
+		}
+		out = append(out, in.linePrefix()...)
+		out = append(out, "… ("+commas(more)+" more "+lines+")"...)
+		out = append(out, in.postfix()...)
+		out = append(out, '\n')
+	}
+	for _, line := range in.s.opt.tail {
+		out = append(out, line...)
+	}
+	in.s.opt.elided = 0
+	in.s.opt.tail = nil
+	_, err := in.write(out)
+	return err
+}
+
+// commas returns n formatted with commas between groups of three digits.
+func commas(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// WithEagerPrefix causes the chain of writers to write the prefix as soon as
+// a line is ended rather than waiting for the next line to be started.  When
+// mirroring an interactive session to a terminal this shows the prefix while
+// waiting for more output.  The prefix written is that of the writer that
+// ended the line.  If the next line is written by a writer nested within it,
+// the rest of the nested writer's prefix is written then.  A prefix that has
+// been written cannot be taken back, so a line written next by a writer with
+// a shorter prefix follows the longer one, and output that ends with a newline
+// is followed by a prefix, even once the writer is closed.
+func WithEagerPrefix() Option {
+	return func(in *indenter) {
+		in.s.opts().eager = true
+		in.s.slow = true
+	}
+}
+
+// WithPrefixFunc causes the prefix the writer being created adds to each line
+// to be the result of calling fn when the line is started, rather than the
+// prefix passed to New.  The argument to fn is the number of the line, where
+// the first line started by the chain of writers is line 1.  This supports
+// prefixes such as timestamps, line numbers, or markers that change from line
+// to line.  A writer later nested within the writer uses the prefix of the
+// line being written when it was created.
+func WithPrefixFunc(fn func(line int) string) Option {
+	return func(in *indenter) {
+		in.opts().prefixFunc = fn
+		in.s.opts() // transformWrite keeps its state in s.opt
+		in.s.slow = true
+	}
+}
+
+// startLine is called when a line is started.  It returns the prefix of the
+// line, calling the function set by WithPrefixFunc if there is one.
+func (in *indenter) startLine() []byte {
+	if in.opt == nil || in.opt.prefixFunc == nil {
+		return in.linePrefix()
+	}
+	in.s.opt.lineNo++
+	var p []byte
+	if in.p != nil {
+		p = in.p.prefix
+	}
+	in.setPrefix(append(p[:len(p):len(p)], in.opt.prefixFunc(in.s.opt.lineNo)...))
+	in.padPrefix()
+	in.expandPrefix()
+	return in.linePrefix()
+}
+
+// transformWrite is the Write used when the chain has options that transform
+// the lines being written.  It builds the output in an outbuf so the number of
+// input bytes written can be determined after a short write.
+func (in *indenter) transformWrite(buf []byte) (int, error) {
+	o := &in.s.opt.out
+	prefix := in.linePrefix()
+	sol := in.s.sol
+	if early := in.s.opt.early; early != nil {
+		// The line was started with the prefix of the writer that
+		// ended the last one.
+		if !sol && len(prefix) > len(early) && bytes.HasPrefix(prefix, early) {
+			o.add(prefix[len(early):])
+		}
+		in.s.opt.early = nil
+	}
+	page := -1 // where the last page started in o.buf
+	limited := in.s.opt.maxLines > 0 || in.s.opt.maxBytes > 0
+	var lines, counted int // lines in o.buf[:counted]
+	for rest := buf; len(rest) > 0; {
+		if limited && sol && !in.s.opt.eliding {
+			lines += bytes.Count(o.buf[counted:], []byte{'\n'})
+			counted = len(o.buf)
+			in.s.opt.eliding = in.overLimit(lines, len(o.buf))
+		}
+		if in.s.opt.eliding {
+			rest = in.elide(o, rest, prefix, sol)
+			sol = true
+			continue
+		}
+		if in.s.opt.formFeed && rest[0] == '\f' {
+			if !sol {
+				in.endLine(o)
+				o.addString("\n")
+			}
+			o.drop(1)
+			o.add(in.s.opt.divider)
+			page = len(o.buf)
+			sol = true
+			rest = rest[1:]
+			continue
+		}
+		if sol {
+			prefix = in.startLine()
+			if in.s.opt.afterIndent {
+				in.s.opt.pending = true
+			} else {
+				in.addPrefix(o, prefix)
+			}
+			sol = false
+		}
+		line := rest
+		x := bytes.IndexByte(rest, '\n')
+		if x >= 0 {
+			line = rest[:x+1]
+		}
+		if f := bytes.IndexByte(line, '\f'); f >= 0 && in.s.opt.formFeed {
+			line = line[:f]
+		} else if x >= 0 {
+			sol = true
+		}
+		rest = rest[len(line):]
+		if !in.transformLine(o, line) {
+			sol = false
+			break
+		}
+	}
+	eager := sol && in.s.opt.eager
+	var early []byte
+	if eager {
+		early = in.startLine()
+		in.addPrefix(o, early)
+	}
+
+	r, err := in.write(o.buf)
+	n := o.consumed(r)
+	full := r == len(o.buf)
+	if err == nil && n < len(buf) {
+		err = ErrLineTooLong
+	}
+	if page >= 0 && full {
+		in.s.lines = int64(bytes.Count(o.buf[page:], []byte{'\n'}))
+	}
+	o.buf = o.buf[:0]
+	o.segs = o.segs[:0]
+	if !in.s.reuse {
+		// Do not hold on to the memory of a large write.
+		*o = outbuf{}
+	}
+	if n > 0 {
+		in.s.sol = buf[n-1] == '\n' || in.s.opt.formFeed && buf[n-1] == '\f'
+		if eager && full {
+			in.s.sol = false
+			in.s.opt.early = early
+		}
+	}
+	return n, err
+}
+
+// addPrefix adds prefix, which starts a line, to o.
+func (in *indenter) addPrefix(o *outbuf, prefix []byte) {
+	if len(prefix) > 0 {
+		o.add(prefix)
+		in.s.stats.Prefixes++
+	}
+}
+
+// transformLine adds line, which is either a complete line ending in a
+// newline or the start of a line, to o after applying the transformations.
+// The prefix has already been added.  It returns false if the rest of the
+// input must not be written.
+func (in *indenter) transformLine(o *outbuf, line []byte) bool {
+	nl := len(line) > 0 && line[len(line)-1] == '\n'
+	if nl {
+		line = line[:len(line)-1]
+	}
+	if !in.stripANSI(o, line) {
+		return false
+	}
+	if nl {
+		in.endLine(o)
+		o.copyByte('\n')
+	}
+	return true
+}
+
+// endLine adds what ends the current line, other than its newline, to o and
+// resets the state of the line.
+func (in *indenter) endLine(o *outbuf) {
+	if len(in.s.opt.held) > 0 {
+		in.putLine(o, in.s.opt.held, true)
+		in.s.opt.held = in.s.opt.held[:0]
+	}
+	if in.s.opt.pending {
+		in.addPrefix(o, in.linePrefix())
+		in.s.opt.pending = false
+	}
+	o.add(in.postfix())
+	in.s.opt.aligned = false
+	in.s.opt.dcol = 0
+	in.s.opt.col = 0
+	in.s.opt.esc = escNone
+	in.s.opt.inText = false
+}
+
+// The states of the ANSI escape sequence parser.
+const (
+	escNone      = iota // not in an escape sequence
+	escStart            // after the ESC
+	escCSI              // in a control sequence, ESC [
+	escString           // in a string, such as an OSC, ESC ]
+	escStringEnd        // after an ESC in a string
+)
+
+// stripANSI passes text, which does not contain a newline, on to dropCRs
+// after removing ANSI escape sequences if WithStripANSI was used.  An escape
+// sequence may be split across writes but not across lines.
+func (in *indenter) stripANSI(o *outbuf, text []byte) bool {
+	if !in.s.opt.stripANSI {
+		return in.dropCRs(o, text)
+	}
+	for len(text) > 0 {
+		if in.s.opt.esc == escNone {
+			x := bytes.IndexByte(text, 0x1b)
+			if x < 0 {
+				return in.dropCRs(o, text)
+			}
+			if !in.dropCRs(o, text[:x]) {
+				return false
+			}
+			text = text[x:]
+		}
+		n := 0
+		for _, c := range text {
+			n++
+			if in.s.opt.esc = nextEsc(in.s.opt.esc, c); in.s.opt.esc == escNone {
+				break
+			}
+		}
+		o.drop(n)
+		text = text[n:]
+	}
+	return true
+}
+
+// nextEsc returns the state of the escape sequence parser after c.
+func nextEsc(state int, c byte) int {
+	switch state {
+	case escNone:
+		if c == 0x1b {
+			return escStart
+		}
+	case escStart:
+		switch c {
+		case '[':
+			return escCSI
+		case ']', 'P', 'X', '^', '_':
+			return escString
+		}
+		if c >= 0x20 && c <= 0x2f {
+			// An intermediate byte, such as the ( in ESC ( B.
+			return escStart
+		}
+	case escCSI:
+		if c < 0x40 || c > 0x7e {
+			return escCSI
+		}
+	case escString:
+		switch c {
+		case 0x07:
+			return escNone
+		case 0x1b:
+			return escStringEnd
+		}
+		return escString
+	case escStringEnd:
+		if c != '\\' {
+			return escString
+		}
+	}
+	return escNone
+}
+
+// dropCRs passes text, which does not contain a newline, on to copyLine after
+// removing carriage returns if WithDropCR was used.
+func (in *indenter) dropCRs(o *outbuf, text []byte) bool {
+	if in.s.opt.dropCR {
+		for {
+			x := bytes.IndexByte(text, '\r')
+			if x < 0 {
+				break
+			}
+			if !in.showSpace(o, text[:x]) {
+				return false
+			}
+			o.drop(1)
+			text = text[x+1:]
+		}
+	}
+	return in.showSpace(o, text)
+}
+
+// showSpace passes text, which does not contain a newline, on to
+// alignComment after making its leading whitespace visible if WithVisibleWhitespace was
+// used.  A prefix delayed by WithPrefixAfterIndent is added after the leading
+// whitespace.
+func (in *indenter) showSpace(o *outbuf, text []byte) bool {
+	if !in.s.opt.visible && !in.s.opt.pending {
+		return in.alignComment(o, text)
+	}
+	if len(text) == 0 || in.s.opt.inText {
+		return in.alignComment(o, text)
+	}
+	n := len(text) - len(bytes.TrimLeft(text, " \t"))
+	if in.s.opt.visible {
+		for _, c := range text[:n] {
+			mark := "·"
+			if c == '\t' {
+				mark = "»"
+			}
+			if !in.putLine(o, []byte(mark), true) {
+				return false
+			}
+			o.drop(1)
+			in.s.opt.dcol++
+		}
+	} else if !in.alignComment(o, text[:n]) {
+		return false
+	}
+	text = text[n:]
+	if len(text) > 0 {
+		in.s.opt.inText = true
+		if in.s.opt.pending {
+			in.addPrefix(o, in.linePrefix())
+			in.s.opt.pending = false
+		}
+	}
+	return in.alignComment(o, text)
+}
+
+// alignComment passes text, which does not contain a newline, on to
+// copyLine after aligning the marker set by WithCommentColumn.  The
+// whitespace, and any partial marker, at the end of text are held until it is
+// known whether the marker follows them.
+func (in *indenter) alignComment(o *outbuf, text []byte) bool {
+	if len(in.s.opt.marker) == 0 || in.s.opt.aligned {
+		return in.copyLine(o, text)
+	}
+	held := len(in.s.opt.held)
+	data := append(in.s.opt.held, text...)
+	// put writes data[i:j] to o.  The held bytes were already consumed so
+	// they are added rather than copied.
+	put := func(i, j int) bool {
+		if i < held {
+			k := j
+			if k > held {
+				k = held
+			}
+			if !in.putLine(o, data[i:k], true) {
+				return false
+			}
+			i = k
+		}
+		return i >= j || in.copyLine(o, data[i:j])
+	}
+	// drop drops the bytes of text in data[i:j].
+	drop := func(i, j int) {
+		if i < held {
+			i = held
+		}
+		if i < j {
+			o.drop(j - i)
+		}
+	}
+
+	if x := bytes.Index(data, in.s.opt.marker); x >= 0 {
+		end := len(bytes.TrimRight(data[:x], " \t"))
+		if !put(0, end) {
+			return false
+		}
+		in.advance(data[:end])
+		drop(end, x)
+		pad := in.s.opt.commentCol - displayWidth(b2s(in.linePrefix())) - in.s.opt.dcol
+		if pad < 1 {
+			pad = 1
+		}
+		if !in.putLine(o, bytes.Repeat([]byte{' '}, pad), true) {
+			return false
+		}
+		in.s.opt.aligned = true
+		in.s.opt.held = in.s.opt.held[:0]
+		return put(x, len(data))
+	}
+
+	// Hold any start of the marker and the whitespace before it.
+	cut := len(data)
+	for k := len(in.s.opt.marker) - 1; k > 0; k-- {
+		if bytes.HasSuffix(data, in.s.opt.marker[:k]) {
+			cut -= k
+			break
+		}
+	}
+	for cut > 0 && (data[cut-1] == ' ' || data[cut-1] == '\t') {
+		cut--
+	}
+	if !put(0, cut) {
+		return false
+	}
+	in.advance(data[:cut])
+	drop(cut, len(data))
+	in.s.opt.held = append(in.s.opt.held[:0], data[cut:]...)
+	return true
+}
+
+// advance advances the display column of the line past text.
+func (in *indenter) advance(text []byte) {
+	off := displayWidth(b2s(in.linePrefix()))
+	for len(text) > 0 {
+		r, n := utf8.DecodeRune(text)
+		in.s.opt.dcol += runeWidth(r, off+in.s.opt.dcol)
+		text = text[n:]
+	}
+}
+
+// copyLine copies text, which does not contain a newline, to o while
+// enforcing the maximum line length.  It returns false if the line is too long
+// and the action is Fail.
+func (in *indenter) copyLine(o *outbuf, text []byte) bool {
+	return in.putLine(o, text, false)
+}
+
+// putLine is copyLine for text that is either from the input or, if added is
+// set, is being added to the output.
+func (in *indenter) putLine(o *outbuf, text []byte, added bool) bool {
+	put := o.copy
+	if added {
+		put = o.add
+	}
+	if in.s.opt.maxLine <= 0 {
+		put(text)
+		return true
+	}
+	prefix := in.linePrefix()
+	room := in.s.opt.maxLine - len(prefix) - len(in.postfix())
+	if room < 1 {
+		room = 1
+	}
+	for in.s.opt.col+len(text) > room {
+		n := room - in.s.opt.col
+		if n < 0 {
+			n = 0
+		}
+		for n > 0 && !utf8.RuneStart(text[n]) {
+			n--
+		}
+		switch in.s.opt.onExceed {
+		case Truncate:
+			put(text[:n])
+			if !added {
+				o.drop(len(text) - n)
+			}
+			in.s.opt.col = room
+			return true
+		case Fail:
+			put(text[:n])
+			in.s.opt.col += n
+			return false
+		}
+		if n == 0 && in.s.opt.col == 0 {
+			// A single rune is wider than the room on a line.
+			_, n = utf8.DecodeRune(text)
+		}
+		put(text[:n])
+		o.add(in.postfix())
+		o.addString("\n")
+		in.addPrefix(o, prefix)
+		text = text[n:]
+		in.s.opt.col = 0
+	}
+	put(text)
+	in.s.opt.col += len(text)
+	return true
+}
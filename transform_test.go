@@ -0,0 +1,468 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWithDropCR(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{in: []string{"a\r\nb\r\n"}, out: "> a\n> b\n"},
+		{in: []string{"a\r", "\nb"}, out: "> a\n> b"},
+		{in: []string{"\r\n"}, out: "> \n"},
+		{in: []string{"50%\r100%\n"}, out: "> 50%100%\n"},
+		{in: []string{"a", "b\n"}, out: "> ab\n"},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", WithDropCR())
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestWithDropCRNested(t *testing.T) {
+	var buf bytes.Buffer
+	w1 := New(&buf, "1> ", WithDropCR())
+	w2 := New(w1, "2> ")
+	io.WriteString(w1, "a\r\n")
+	io.WriteString(w2, "b\r\n")
+	w2.(interface {
+		WriteAll(...[]byte) (int, error)
+	}).WriteAll([]byte("c\r"), []byte("\n"))
+	if got, want := buf.String(), "1> a\n1> 2> b\n1> 2> c\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithDropCRShort(t *testing.T) {
+	for _, tt := range []struct {
+		left int
+		n    int
+		sol  bool
+	}{
+		{left: 0, n: 0, sol: true},
+		{left: 1, n: 0, sol: true},
+		{left: 3, n: 2, sol: false}, // "> a", the \r is consumed
+		{left: 4, n: 3, sol: true},  // "> a\n", the \r was dropped
+		{left: 6, n: 3, sol: true},  // "> a\n> "
+		{left: 7, n: 5, sol: false}, // "> a\n> b"
+		{left: 8, n: 6, sol: true},  // everything
+		{left: 20, n: 6, sol: true}, // everything
+	} {
+		fw := &fakeWriter{left: tt.left}
+		w := New(fw, "> ", WithDropCR())
+		n, _ := io.WriteString(w, "a\r\nb\r\n")
+		if n != tt.n {
+			t.Errorf("left %d: got %d, want %d", tt.left, n, tt.n)
+		}
+		if sol := w.(*indenter).s.sol; sol != tt.sol {
+			t.Errorf("left %d: got sol %v, want %v", tt.left, sol, tt.sol)
+		}
+	}
+}
+
+func TestWithMaxLineLen(t *testing.T) {
+	for _, tt := range []struct {
+		action Action
+		in     []string
+		out    string
+		n      int
+		err    error
+	}{
+		{action: Wrap, in: []string{"abc\n"}, out: "> abc\n"},
+		{action: Wrap, in: []string{"abcdefgh\n"}, out: "> abcde\n> fgh\n"},
+		{action: Wrap, in: []string{"abc", "defgh", "ijkl\n"}, out: "> abcde\n> fghij\n> kl\n"},
+		{action: Wrap, in: []string{"abcde\nf"}, out: "> abcde\n> f"},
+		{action: Wrap, in: []string{"abcd日本\n"}, out: "> abcd\n> 日\n> 本\n"},
+		{action: Truncate, in: []string{"abcdefgh\nij\n"}, out: "> abcde\n> ij\n"},
+		{action: Truncate, in: []string{"abc", "defgh", "\n"}, out: "> abcde\n"},
+		{action: Fail, in: []string{"abc\n"}, out: "> abc\n"},
+		{action: Fail, in: []string{"ab\nabcdefgh\n"}, out: "> ab\n> abcde", n: 8, err: ErrLineTooLong},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", WithMaxLineLen(7, tt.action))
+		var n int
+		var err error
+		for _, s := range tt.in {
+			var nn int
+			nn, err = io.WriteString(w, s)
+			n += nn
+			if err != nil {
+				break
+			}
+		}
+		if tt.err == nil {
+			tt.n = len(strings.Join(tt.in, ""))
+		}
+		if n != tt.n || err != tt.err {
+			t.Errorf("%v %q: got %d, %v, want %d, %v", tt.action, tt.in, n, err, tt.n, tt.err)
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%v %q: got %q, want %q", tt.action, tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestWithMaxLineLenPostfix(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewPostfix(&buf, "| ", " |")
+	WithMaxLineLen(8, Wrap)(w.(*indenter))
+	io.WriteString(w, "abcdef\n")
+	if got, want := buf.String(), "| abcd |\n| ef |\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithEagerPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{in: []string{"a\n"}, out: "> a\n> "},
+		{in: []string{"a\n", "b"}, out: "> a\n> b"},
+		{in: []string{"a\nb\n", "c\n"}, out: "> a\n> b\n> c\n> "},
+		{in: []string{"a", "\n", "\n"}, out: "> a\n> \n> "},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", WithEagerPrefix())
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestWithEagerPrefixNested(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "> ", WithEagerPrefix())
+	w2 := New(w, "..")
+	io.WriteString(w, "a\n")
+	io.WriteString(w2, "b\n")
+	// The outer writer's line follows the nested prefix already written,
+	// and the prefix written after the last line is left by Close.
+	io.WriteString(w, "c\n")
+	w2.(io.Closer).Close()
+	w.(io.Closer).Close()
+	if got, want := buf.String(), "> a\n> ..b\n> ..c\n> "; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithEagerPrefixShort(t *testing.T) {
+	// The prefix after the newline is not written so it must be written
+	// again with the next line.
+	fw := &fakeWriter{left: 5}
+	w := New(fw, "> ", WithEagerPrefix())
+	n, _ := io.WriteString(w, "a\n")
+	if n != 2 {
+		t.Errorf("got %d, want 2", n)
+	}
+	if !w.(*indenter).s.sol {
+		t.Errorf("not at the start of a line")
+	}
+}
+
+func TestWithPrefixFunc(t *testing.T) {
+	number := func(line int) string { return strconv.Itoa(line) + ": " }
+	for _, tt := range []struct {
+		opts []Option
+		in   []string
+		out  string
+	}{
+		{in: []string{"a\nb\n"}, out: "1: a\n2: b\n"},
+		{in: []string{"a", "b\n", "\n", "c"}, out: "1: ab\n2: \n3: c"},
+		{opts: []Option{WithEagerPrefix()}, in: []string{"a\n", "b\n"}, out: "1: a\n2: b\n3: "},
+		{opts: []Option{WithPrefixAfterIndent()}, in: []string{"  a\n\tb\n"}, out: "  1: a\n\t2: b\n"},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "", append(tt.opts, WithPrefixFunc(number))...)
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+
+	// Nested writers extend the prefix of the line being written.
+	var buf bytes.Buffer
+	w := New(New(&buf, "| "), "", WithPrefixFunc(number))
+	io.WriteString(w, "a\n")
+	io.WriteString(New(w, "  "), "b\n")
+	io.WriteString(w, "c\n")
+	if got, want := buf.String(), "| 1: a\n| 1:   b\n| 2: c\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithStripANSI(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{in: []string{"\x1b[1;31mred\x1b[0m\n"}, out: "> red\n"},
+		{in: []string{"a\x1b[", "31", "mb\n"}, out: "> ab\n"},
+		{in: []string{"\x1b]0;title\x07a\n"}, out: "> a\n"},
+		{in: []string{"\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\\n"}, out: "> link\n"},
+		{in: []string{"\x1b(Ba\x1bcb\n"}, out: "> ab\n"},
+		{in: []string{"a\x1b[\nb\n"}, out: "> a\n> b\n"},
+		{in: []string{"plain\n"}, out: "> plain\n"},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", WithStripANSI())
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestWithStripANSIPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "\x1b[2m|\x1b[0m ", WithStripANSI(), WithDropCR())
+	io.WriteString(w, "\x1b[1mbold\x1b[0m\r\n")
+	if got, want := buf.String(), "\x1b[2m|\x1b[0m bold\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithVisibleWhitespace(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{in: []string{"\t  a b\n"}, out: "> »··a b\n"},
+		{in: []string{" ", "\t", "a\t\n"}, out: "> ·»a\t\n"},
+		{in: []string{"a\n  b\n"}, out: "> a\n> ··b\n"},
+		{in: []string{"  \n"}, out: "> ··\n"},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", WithVisibleWhitespace())
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestWithVisibleWhitespaceShort(t *testing.T) {
+	// "> " and the marker for the first space, which is 2 bytes.
+	fw := &fakeWriter{left: 4}
+	w := New(fw, "> ", WithVisibleWhitespace())
+	if n, _ := io.WriteString(w, "  a\n"); n != 1 {
+		t.Errorf("got %d, want 1", n)
+	}
+}
+
+func TestWithPrefixAfterIndent(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{in: []string{"\tfoo\n"}, out: "\t// foo\n"},
+		{in: []string{"a\n  b\n\n"}, out: "// a\n  // b\n// \n"},
+		{in: []string{"\t", "\t", "x", "y\n"}, out: "\t\t// xy\n"},
+		{in: []string{"  \n"}, out: "  // \n"},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "// ", WithPrefixAfterIndent())
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestWithPrefixAfterIndentVisible(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "# ", WithPrefixAfterIndent(), WithVisibleWhitespace())
+	w = New(w, "> ")
+	io.WriteString(w, " \tx\n")
+	if got, want := buf.String(), "·»# > x\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithCommentColumn(t *testing.T) {
+	for _, tt := range []struct {
+		in  []string
+		out string
+	}{
+		{in: []string{"x = 1 # one\n"}, out: "> x = 1     # one\n"},
+		{in: []string{"long_name = 1 # one\n"}, out: "> long_name = 1 # one\n"},
+		{in: []string{"no comment  \n"}, out: "> no comment  \n"},
+		{in: []string{"a\t# tab\n"}, out: "> a         # tab\n"},
+		{in: []string{"\tx # t\n"}, out: "> \tx   # t\n"},
+		{in: []string{"日本 # wide\n"}, out: "> 日本      # wide\n"},
+		{in: []string{"x ", " ", "#", " y # z\n"}, out: "> x         # y # z\n"},
+		{in: []string{"x", " #", "#y\n"}, out: "> x         ##y\n"},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", WithCommentColumn("#", 12))
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestWithCommentColumnFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf, "", WithCommentColumn("//", 8))
+	io.WriteString(w, "a  /")
+	if got, want := buf.String(), "a"; got != want {
+		t.Errorf("before Flush got %q, want %q", got, want)
+	}
+	w.(interface{ Flush() error }).Flush()
+	if got, want := buf.String(), "a  /"; got != want {
+		t.Errorf("after Flush got %q, want %q", got, want)
+	}
+}
+
+func TestWithFormFeed(t *testing.T) {
+	for _, tt := range []struct {
+		divider string
+		in      []string
+		out     string
+		lines   int64
+	}{
+		{divider: "----", in: []string{"a\n\fb\n"}, out: "> a\n----\n> b\n", lines: 1},
+		{divider: "----\n", in: []string{"a\fb"}, out: "> a\n----\n> b", lines: 0},
+		{divider: "", in: []string{"a\n", "\f", "b\nc\n"}, out: "> a\n> b\n> c\n", lines: 2},
+		{divider: "==", in: []string{"\f\f"}, out: "==\n==\n", lines: 0},
+		{divider: "==", in: []string{"a\n"}, out: "> a\n", lines: 1},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", WithFormFeed(tt.divider))
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+		if got := w.(interface{ Snapshot() State }).Snapshot().Lines; got != tt.lines {
+			t.Errorf("%q: got %d lines, want %d", tt.in, got, tt.lines)
+		}
+	}
+}
+
+func TestWithFormFeedPostfix(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewPostfix(&buf, "| ", " |")
+	WithFormFeed("")(w.(*indenter))
+	io.WriteString(w, "a\fb\n")
+	if got, want := buf.String(), "| a |\n| b |\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithMaxLines(t *testing.T) {
+	for _, tt := range []struct {
+		opts []Option
+		in   []string
+		out  string
+	}{
+		{
+			opts: []Option{WithMaxLines(2)},
+			in:   []string{"1\n2\n3\n4\n5"},
+			out:  "> 1\n> 2\n> … (3 more lines)\n",
+		},
+		{
+			opts: []Option{WithMaxLines(2)},
+			in:   []string{"1\n", "2\n", "3", "a\n"},
+			out:  "> 1\n> 2\n> … (1 more line)\n",
+		},
+		{
+			opts: []Option{WithMaxLines(5)},
+			in:   []string{"1\n2\n"},
+			out:  "> 1\n> 2\n",
+		},
+		{
+			opts: []Option{WithMaxLines(1), WithKeepLast(2)},
+			in:   []string{"1\n2\n3\n4", "x\n5\n"},
+			out:  "> 1\n> … (2 more lines)\n> 4x\n> 5\n",
+		},
+		{
+			opts: []Option{WithMaxLines(1), WithKeepLast(5)},
+			in:   []string{"1\n2\n"},
+			out:  "> 1\n> 2\n",
+		},
+		{
+			opts: []Option{WithMaxBytes(5)},
+			in:   []string{"abc\ndefg\nh\n"},
+			out:  "> abc\n> … (2 more lines)\n",
+		},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, "> ", tt.opts...)
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if err := w.(io.Closer).Close(); err != nil {
+			t.Errorf("%q: Close: %v", tt.in, err)
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestCommas(t *testing.T) {
+	for n, want := range map[int64]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567"} {
+		if got := commas(n); got != want {
+			t.Errorf("commas(%d) got %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+// WithTrimTrailingSpace causes the chain of writers to remove the spaces and
+// tabs at the end of each line, including those of a prefix that ends in a
+// space when the line is blank.  Since more text may follow, whitespace at the
+// end of a write that does not end a line is held until it is known whether
+// or not it ends the line.  Whitespace held when the writer is closed is
+// discarded as it ends the last line.
+func WithTrimTrailingSpace() Option {
+	return func(in *indenter) {
+		in.s.trim = true
+		in.s.opts() // trimWrite holds whitespace in s.opt
+	}
+}
+
+// trimSpace appends buf to out with the spaces and tabs that end each line
+// removed.  The whitespace in held, at the end of the previous buf, is added
+// to out before the next byte that is not whitespace.  trimSpace stops before
+// a byte that would make out longer than max, if max is not negative.  It
+// returns out, the whitespace now held, and the number of bytes of buf
+// processed.
+func trimSpace(out, held, buf []byte, max int) ([]byte, []byte, int) {
+	for i, c := range buf {
+		switch c {
+		case ' ', '\t':
+			held = append(held, c)
+			continue
+		case '\n':
+			held = held[:0]
+		default:
+			if len(held) > 0 {
+				if max >= 0 && len(out)+len(held) > max {
+					// Only part of the whitespace was written.
+					n := max - len(out)
+					out = append(out, held[:n]...)
+					return out, append(held[:0], held[n:]...), i
+				}
+				out = append(out, held...)
+				held = held[:0]
+			}
+		}
+		if max >= 0 && len(out) >= max {
+			return out, held, i
+		}
+		out = append(out, c)
+	}
+	return out, held, len(buf)
+}
+
+// trimWrite writes nbuf, with the trailing whitespace of its lines removed, to
+// the underlying writer.  The whitespace held from a previous write, and held
+// for the next write, is reported as written.
+func (in *indenter) trimWrite(nbuf []byte) (int, error) {
+	prev := string(in.s.opt.ws)
+	out := in.s.opt.trimmed[:0]
+	if need := len(in.s.opt.ws) + len(nbuf); cap(out) < need {
+		out = make([]byte, 0, need)
+	}
+	out, ws, _ := trimSpace(out, in.s.opt.ws, nbuf, -1)
+	in.s.opt.ws = ws
+	if in.s.reuse {
+		in.s.opt.trimmed = out
+	}
+	r, err := in.output(out)
+	if r == len(out) {
+		return len(nbuf), err
+	}
+	// Find out how much of nbuf made it to the underlying writer.
+	_, ws, n := trimSpace(out[:0], []byte(prev), nbuf, r)
+	in.s.opt.ws = ws
+	return n, err
+}
@@ -0,0 +1,82 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWithTrimTrailingSpace(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		opts   []Option
+		in     []string
+		out    string
+	}{
+		{prefix: "> ", in: []string{"a  \n\nb\t\n"}, out: "> a\n>\n> b\n"},
+		{prefix: "> ", in: []string{"a ", " ", "b \n"}, out: "> a  b\n"},
+		{prefix: "> ", in: []string{"a ", "\n", " \n"}, out: "> a\n>\n"},
+		{prefix: "> ", in: []string{"a b"}, out: "> a b"},
+		{prefix: "> ", in: []string{"a "}, out: "> a"},
+		{prefix: "\t", in: []string{"\n \n"}, out: "\n\n"},
+		{prefix: "> ", opts: []Option{WithDropCR()}, in: []string{"a \r\n \r\n"}, out: "> a\n>\n"},
+	} {
+		var buf bytes.Buffer
+		w := New(&buf, tt.prefix, append(tt.opts, WithTrimTrailingSpace())...)
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		w.(io.Closer).Close()
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestWithTrimTrailingSpaceShort(t *testing.T) {
+	for _, tt := range []struct {
+		max  int
+		n    int
+		out  string
+		more string
+	}{
+		// "> a  b \n> c\n" is written as "> a  b\n> c\n".
+		{max: 3, n: 3, out: "> a", more: "  b\n> c\n"},
+		{max: 4, n: 3, out: "> a ", more: " b\n> c\n"},
+		{max: 6, n: 5, out: "> a  b", more: "\n> c\n"},
+		{max: 7, n: 6, out: "> a  b\n", more: "> c\n"},
+	} {
+		fw := &fakeWriter{left: tt.max}
+		w := New(fw, "> ", WithTrimTrailingSpace())
+		in := "a  b \nc\n"
+		n, _ := io.WriteString(w, in)
+		if n != tt.n {
+			t.Errorf("max %d: got %d, want %d", tt.max, n, tt.n)
+		}
+		if got := fw.buf.String(); got != tt.out {
+			t.Errorf("max %d: got %q, want %q", tt.max, got, tt.out)
+		}
+		fw.buf.Reset()
+		fw.left = 100
+		io.WriteString(w, in[n:])
+		if got := fw.buf.String(); got != tt.more {
+			t.Errorf("max %d: then got %q, want %q", tt.max, got, tt.more)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// TransformTxtar returns a copy of the txtar archive, as produced by Format in
+// golang.org/x/tools/txtar, with the data of each file whose name matches
+// pattern replaced by f(data).  Pattern uses the syntax of path.Match.  The
+// comment, the file markers, and the files that do not match are left as is.
+// As with txtar.Format, a newline is added to the end of transformed data that
+// does not end in one.  TransformTxtar returns an error if pattern is malformed
+// or a transformed file contains a line that would be taken as a file marker,
+// which would change the structure of the archive.
+//
+// Working on the formatted archive means this package does not depend on the
+// txtar package.  An archive a is transformed with:
+//
+//	data, err := indent.TransformTxtar(txtar.Format(a), "*.go", f)
+//	if err != nil {
+//		...
+//	}
+//	a = txtar.Parse(data)
+func TransformTxtar(archive []byte, pattern string, f func(data []byte) []byte) ([]byte, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.Grow(len(archive))
+	match := false
+	var data []byte // data of the current file if it matches
+	name := ""
+	finish := func() error {
+		if !match {
+			return nil
+		}
+		data = f(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			data = append(data, '\n')
+		}
+		for rest := data; len(rest) > 0; {
+			var line []byte
+			line, rest = nextLine(rest)
+			if _, ok := txtarMarker(line); ok {
+				return fmt.Errorf("indent: transformed txtar file %s contains file marker %q", name, bytes.TrimSuffix(line, []byte{'\n'}))
+			}
+		}
+		out.Write(data)
+		return nil
+	}
+	for rest := archive; len(rest) > 0; {
+		var line []byte
+		line, rest = nextLine(rest)
+		if n, ok := txtarMarker(line); ok {
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			out.Write(line)
+			name = n
+			match, _ = path.Match(pattern, name)
+			data = nil
+			continue
+		}
+		if match {
+			data = append(data, line...)
+		} else {
+			out.Write(line)
+		}
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// txtarMarker returns the file name in line if line is a txtar file marker,
+// "-- name --".
+func txtarMarker(line []byte) (string, bool) {
+	s := strings.TrimSuffix(strings.TrimSuffix(string(line), "\n"), "\r")
+	if !strings.HasPrefix(s, "-- ") || !strings.HasSuffix(s, " --") || len(s) < 6 {
+		return "", false
+	}
+	name := strings.TrimSpace(s[3 : len(s)-3])
+	return name, name != ""
+}
+
+// IndentTxtar indents the lines of the files in the txtar archive whose names
+// match pattern with prefix.  See TransformTxtar.
+func IndentTxtar(archive []byte, pattern, prefix string) ([]byte, error) {
+	return TransformTxtar(archive, pattern, func(data []byte) []byte {
+		return Bytes([]byte(prefix), data)
+	})
+}
+
+// DedentTxtar removes the longest common leading whitespace from the lines of
+// the files in the txtar archive whose names match pattern.  See
+// TransformTxtar.
+func DedentTxtar(archive []byte, pattern string) ([]byte, error) {
+	return TransformTxtar(archive, pattern, func(data []byte) []byte {
+		return []byte(Dedent(string(data)))
+	})
+}
+
+// RetabTxtar replaces each leading occurrence of from with to on every line of
+// the files in the txtar archive whose names match pattern.  For example, a
+// from of "\t" and a to of "    " converts indentation by tabs to indentation
+// by four spaces.  See TransformTxtar.
+func RetabTxtar(archive []byte, pattern, from, to string) ([]byte, error) {
+	return TransformTxtar(archive, pattern, func(data []byte) []byte {
+		return retab(data, from, to)
+	})
+}
+
+// retab replaces each leading occurrence of from with to on every line of
+// data.
+func retab(data []byte, from, to string) []byte {
+	if from == "" {
+		return data
+	}
+	var out []byte
+	for len(data) > 0 {
+		var line []byte
+		line, data = nextLine(data)
+		for bytes.HasPrefix(line, []byte(from)) {
+			out = append(out, to...)
+			line = line[len(from):]
+		}
+		out = append(out, line...)
+	}
+	return out
+}
@@ -0,0 +1,147 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"strings"
+	"testing"
+)
+
+const testArchive = `comment
+-- a.go --
+package a
+
+func A() {
+	return
+}
+-- b.txt --
+    b
+      c
+-- c/d.go --
+	d
+`
+
+func TestTransformTxtar(t *testing.T) {
+	upper := func(data []byte) []byte { return []byte(strings.ToUpper(string(data))) }
+	kept := append(make([]byte, 0, 64), "k\n"...)
+	for _, tt := range []struct {
+		name    string
+		archive string
+		pattern string
+		f       func([]byte) []byte
+		out     string
+		err     string
+	}{{
+		name:    "none",
+		archive: testArchive,
+		pattern: "*.c",
+		f:       upper,
+		out:     testArchive,
+	}, {
+		name:    "b",
+		archive: testArchive,
+		pattern: "b.txt",
+		f:       upper,
+		out:     strings.Replace(testArchive, "    b\n      c\n", "    B\n      C\n", 1),
+	}, {
+		name:    "missing newline",
+		archive: "-- a --\na\n-- b --\nb\n",
+		pattern: "a",
+		f:       func([]byte) []byte { return []byte("x") },
+		out:     "-- a --\nx\n-- b --\nb\n",
+	}, {
+		name:    "empty",
+		archive: "-- a --\na\n-- b --\nb\n",
+		pattern: "*",
+		f:       func([]byte) []byte { return nil },
+		out:     "-- a --\n-- b --\n",
+	}, {
+		name:    "kept by f",
+		archive: "-- a --\na\n-- b --\nb\n",
+		pattern: "*",
+		f:       func([]byte) []byte { return kept },
+		out:     "-- a --\nk\n-- b --\nk\n",
+	}, {
+		name:    "bad pattern",
+		archive: testArchive,
+		pattern: "[",
+		f:       upper,
+		err:     "syntax error in pattern",
+	}, {
+		name:    "marker",
+		archive: "-- a --\na\n",
+		pattern: "a",
+		f:       func([]byte) []byte { return []byte("-- b --\n") },
+		err:     `indent: transformed txtar file a contains file marker "-- b --"`,
+	}} {
+		out, err := TransformTxtar([]byte(tt.archive), tt.pattern, tt.f)
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.err {
+			t.Errorf("%s: got error %q, want %q", tt.name, got, tt.err)
+		}
+		if string(out) != tt.out {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.name, out, tt.out)
+		}
+	}
+}
+
+func TestIndentTxtar(t *testing.T) {
+	got, err := IndentTxtar([]byte(testArchive), "*.go", "> ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `comment
+-- a.go --
+> package a
+> 
+> func A() {
+> 	return
+> }
+-- b.txt --
+    b
+      c
+-- c/d.go --
+	d
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDedentTxtar(t *testing.T) {
+	got, err := DedentTxtar([]byte(testArchive), "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// As with path.Match, * does not match c/d.go.
+	want := strings.Replace(testArchive, "    b\n      c\n", "b\n  c\n", 1)
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRetabTxtar(t *testing.T) {
+	got, err := RetabTxtar([]byte(testArchive), "a.go", "\t", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(testArchive, "\treturn\n", "  return\n", 1)
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
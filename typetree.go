@@ -0,0 +1,117 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// TypeTree returns the structure of the type t as an indented tree.  The first
+// line is the type.  The fields of a struct follow, one per line and indented
+// by prefix, as they would be declared along with their tags.  A field whose
+// type is, or is a pointer, slice, array, or map of, a struct is followed by
+// the fields of that struct indented by one more prefix.  A struct that
+// contains itself is marked as a cycle rather than expanded again.  For
+// example, with a prefix of two spaces
+//
+//	type Node struct {
+//		Name     string `json:"name"`
+//		Children []*Node
+//		Meta     struct{ Size int }
+//	}
+//
+// is displayed as
+//
+//	indent.Node
+//	  Name string `json:"name"`
+//	  Children []*indent.Node (cycle)
+//	  Meta struct
+//	    Size int
+//
+// TypeTree returns "" if t is nil.
+func TypeTree(t reflect.Type, prefix string) string {
+	if t == nil {
+		return ""
+	}
+	var b strings.Builder
+	io.WriteString(&b, typeString(t)+"\n")
+	writeFields(New(&b, prefix), t, prefix, map[reflect.Type]bool{})
+	return b.String()
+}
+
+// writeFields writes the fields of the struct of t, if any, to w.
+func writeFields(w io.Writer, t reflect.Type, prefix string, seen map[reflect.Type]bool) {
+	st := structOf(t)
+	if st == nil {
+		return
+	}
+	seen[st] = true
+	defer delete(seen, st)
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		line := f.Name + " " + typeString(f.Type)
+		if f.Anonymous {
+			line = typeString(f.Type)
+		}
+		if f.Tag != "" {
+			line += " `" + string(f.Tag) + "`"
+		}
+		if fs := structOf(f.Type); fs != nil && seen[fs] {
+			fmt.Fprintf(w, "%s (cycle)\n", line)
+			continue
+		}
+		io.WriteString(w, line+"\n")
+		writeFields(New(w, prefix), f.Type, prefix, seen)
+	}
+}
+
+// structOf returns the struct type t is, or is a pointer, slice, array or map
+// of, or nil.
+func structOf(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Struct:
+			return t
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+}
+
+// typeString returns t as it would be written in a declaration, except that
+// the fields of unnamed structs are omitted as they are displayed below it.
+func typeString(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.String()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return "struct"
+	case reflect.Ptr:
+		return "*" + typeString(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeString(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), typeString(t.Elem()))
+	case reflect.Map:
+		return "map[" + typeString(t.Key()) + "]" + typeString(t.Elem())
+	}
+	return t.String()
+}
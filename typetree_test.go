@@ -0,0 +1,81 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"reflect"
+	"testing"
+)
+
+type ttNode struct {
+	Name     string `json:"name"`
+	Children []*ttNode
+	Meta     struct{ Size int }
+}
+
+type ttBase struct {
+	ID int
+}
+
+type ttRecord struct {
+	ttBase
+	Labels map[string]struct {
+		Value string
+	}
+	Pairs [2]ttBase
+	Any   interface{}
+	f     func() error
+}
+
+func TestTypeTree(t *testing.T) {
+	for _, tt := range []struct {
+		v    interface{}
+		want string
+	}{
+		{
+			v:    0,
+			want: "int\n",
+		},
+		{
+			v: ttNode{},
+			want: `indent.ttNode
+  Name string ` + "`json:\"name\"`" + `
+  Children []*indent.ttNode (cycle)
+  Meta struct
+    Size int
+`,
+		},
+		{
+			v: &ttRecord{},
+			want: `*indent.ttRecord
+  indent.ttBase
+    ID int
+  Labels map[string]struct
+    Value string
+  Pairs [2]indent.ttBase
+    ID int
+  Any interface {}
+  f func() error
+`,
+		},
+	} {
+		if got := TypeTree(reflect.TypeOf(tt.v), "  "); got != tt.want {
+			t.Errorf("TypeTree(%T) got:\n%s\nwant:\n%s", tt.v, got, tt.want)
+		}
+	}
+	if got := TypeTree(nil, "  "); got != "" {
+		t.Errorf("TypeTree(nil) got %q, want \"\"", got)
+	}
+}
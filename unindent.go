@@ -0,0 +1,147 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// An unindenter removes a prefix from the lines written to it.
+type unindenter struct {
+	w      io.Writer
+	prefix []byte
+	out    outbuf
+	err    error
+	sol    bool // we are matching the prefix at the start of a line
+	held   int  // number of bytes of prefix matched so far
+	strict bool // a line without the prefix is an error
+	line   int  // the number of the current line, starting at 1
+}
+
+// ErrMissingPrefix is returned, wrapped in an error that includes the line
+// number, by a reader returned by NewDedentReaderStrict when a line does not
+// start with the prefix.
+var ErrMissingPrefix = errors.New("indent: line does not start with the prefix")
+
+// NewUnindent returns a writer that removes prefix from the start of each line
+// written to it and writes the results to w.  Lines that do not start with
+// prefix are written unchanged.  It is the inverse of New and is used to move
+// already indented text into a context with its own indentation.  To remove
+// one level of indentation, use the unit of indentation, such as "\t" or four
+// spaces, as the prefix.  NewUnindent returns w if prefix is the empty string.
+//
+// A write that ends part way through what might be prefix holds on to those
+// bytes until it is known whether or not they are the prefix.  The Flush and
+// Close methods write any held bytes.
+//
+// Once the writer returns an error all subsequent writes return the same
+// error.
+func NewUnindent(w io.Writer, prefix string) io.Writer {
+	if len(prefix) == 0 {
+		return w
+	}
+	return newUnindenter(w, prefix)
+}
+
+func newUnindenter(w io.Writer, prefix string) *unindenter {
+	return &unindenter{
+		w:      w,
+		prefix: []byte(prefix),
+		sol:    true,
+		line:   1,
+	}
+}
+
+// missing returns the error for the current line not starting with the
+// prefix.
+func (u *unindenter) missing() error {
+	return fmt.Errorf("indent: line %d: %w", u.line, ErrMissingPrefix)
+}
+
+// NewStrip is another name for NewUnindent.  It returns a writer that strips
+// prefix from the start of each line, passing through lines that lack it.
+func NewStrip(w io.Writer, prefix string) io.Writer {
+	return NewUnindent(w, prefix)
+}
+
+func (u *unindenter) Write(buf []byte) (int, error) {
+	if u.err != nil {
+		return 0, u.err
+	}
+	for len(buf) > 0 {
+		if u.sol {
+			c := buf[0]
+			if c == u.prefix[u.held] {
+				u.out.drop(1)
+				buf = buf[1:]
+				if u.held++; u.held == len(u.prefix) {
+					u.sol = false
+					u.held = 0
+				}
+				continue
+			}
+			// Not the prefix after all.
+			if u.strict {
+				u.held = 0
+				n, err := u.out.writeTo(u.w)
+				if err == nil {
+					err = u.missing()
+				}
+				u.err = err
+				return n, err
+			}
+			u.out.add(u.prefix[:u.held])
+			u.sol = false
+			u.held = 0
+		}
+		x := bytes.IndexByte(buf, '\n')
+		if x < 0 {
+			u.out.copy(buf)
+			break
+		}
+		u.out.copy(buf[:x+1])
+		buf = buf[x+1:]
+		u.sol = true
+		u.line++
+	}
+	n, err := u.out.writeTo(u.w)
+	u.err = err
+	return n, err
+}
+
+// Flush writes any bytes held at the start of a line that matched the start
+// of the prefix.
+func (u *unindenter) Flush() error {
+	if u.err != nil || u.held == 0 {
+		return u.err
+	}
+	if u.strict {
+		u.err = u.missing()
+		return u.err
+	}
+	u.out.add(u.prefix[:u.held])
+	u.held = 0
+	u.sol = false
+	_, u.err = u.out.writeTo(u.w)
+	return u.err
+}
+
+// Close calls Flush.  It does not close the underlying writer.
+func (u *unindenter) Close() error {
+	return u.Flush()
+}
@@ -0,0 +1,87 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestNewUnindent(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		in     []string
+		out    string
+		flush  string
+	}{
+		{prefix: "\t", in: []string{"\ta\n\t\tb\nc\n"}, out: "a\n\tb\nc\n"},
+		{prefix: "> ", in: []string{"> a\n>b\n> "}, out: "a\n>b\n"},
+		{prefix: "> ", in: []string{">", " a\n>", "b\n"}, out: "a\n>b\n"},
+		{prefix: "> ", in: []string{"\n> \n"}, out: "\n\n"},
+		{prefix: "> ", in: []string{"x> a\n"}, out: "x> a\n"},
+		{prefix: "> ", in: []string{"a\n>"}, out: "a\n", flush: "a\n>"},
+	} {
+		var buf bytes.Buffer
+		w := NewUnindent(&buf, tt.prefix)
+		for _, s := range tt.in {
+			if n, err := io.WriteString(w, s); n != len(s) || err != nil {
+				t.Errorf("%q: Write(%q) got %d, %v", tt.in, s, n, err)
+			}
+		}
+		if got := buf.String(); got != tt.out {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.out)
+		}
+		if tt.flush == "" {
+			tt.flush = tt.out
+		}
+		if err := w.(io.Closer).Close(); err != nil {
+			t.Errorf("%q: Close: %v", tt.in, err)
+		}
+		if got := buf.String(); got != tt.flush {
+			t.Errorf("%q: after Close got %q, want %q", tt.in, got, tt.flush)
+		}
+	}
+}
+
+func TestNewUnindentInverse(t *testing.T) {
+	in := "a\n\tb\n\n c"
+	var buf bytes.Buffer
+	io.WriteString(New(NewUnindent(&buf, "// "), "// "), in)
+	if got := buf.String(); got != in {
+		t.Errorf("got %q, want %q", got, in)
+	}
+}
+
+func TestNewStrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewStrip(&buf, "[log] ")
+	io.WriteString(w, "[log] one\ntwo\n[log] [log] three\n")
+	if got, want := buf.String(), "one\ntwo\n[log] three\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewUnindentShort(t *testing.T) {
+	fw := &fakeWriter{left: 3}
+	w := NewUnindent(fw, "> ")
+	n, err := io.WriteString(w, "> ab\n> cd\n")
+	if n != 7 || err != io.EOF {
+		t.Errorf("got %d, %v, want 7, %v", n, err, io.EOF)
+	}
+	if _, err := io.WriteString(w, "x"); err != io.EOF {
+		t.Errorf("second write got %v, want %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,56 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Validate returns an error if output is not input indented by prefix, as is
+// returned by Bytes(prefix, input).  It checks that output has the same number
+// of lines as input, that every line of output starts with prefix, and that
+// each line of output, less its prefix, is the corresponding line of input.
+// The error describes the first line that fails.  Validate is intended for
+// use by fuzz and property tests of code built on this package.
+func Validate(prefix, input, output []byte) error {
+	if in, out := bytes.Count(input, []byte{'\n'}), bytes.Count(output, []byte{'\n'}); in != out {
+		return fmt.Errorf("indent: output has %d newlines, input has %d", out, in)
+	}
+	for n := 1; len(input) > 0 || len(output) > 0; n++ {
+		var iline, oline []byte
+		iline, input = nextLine(input)
+		oline, output = nextLine(output)
+		if len(iline) == 0 {
+			return fmt.Errorf("indent: line %d: output %q follows the end of input", n, oline)
+		}
+		if !bytes.HasPrefix(oline, prefix) {
+			return fmt.Errorf("indent: line %d: output %q does not start with prefix %q", n, oline, prefix)
+		}
+		if !bytes.Equal(oline[len(prefix):], iline) {
+			return fmt.Errorf("indent: line %d: output %q is not input %q with prefix %q", n, oline, iline, prefix)
+		}
+	}
+	return nil
+}
+
+// nextLine returns the first line of buf, including its newline, and the rest
+// of buf.
+func nextLine(buf []byte) (line, rest []byte) {
+	if x := bytes.IndexByte(buf, '\n'); x >= 0 {
+		return buf[:x+1], buf[x+1:]
+	}
+	return buf, nil
+}
@@ -0,0 +1,82 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		prefix string
+		in     string
+		out    string
+		err    string
+	}{
+		{prefix: "> ", in: "", out: ""},
+		{prefix: "> ", in: "a", out: "> a"},
+		{prefix: "> ", in: "a\nb\n", out: "> a\n> b\n"},
+		{prefix: "> ", in: "a\n\nb", out: "> a\n> \n> b"},
+		{prefix: "", in: "a\nb", out: "a\nb"},
+		{
+			prefix: "> ", in: "a\nb\n", out: "> a\n> b\n> c\n",
+			err: "indent: output has 3 newlines, input has 2",
+		},
+		{
+			prefix: "> ", in: "a\nb\n", out: "> a\nb\n",
+			err: `indent: line 2: output "b\n" does not start with prefix "> "`,
+		},
+		{
+			prefix: "> ", in: "a\nb\n", out: "> a\n> c\n",
+			err: `indent: line 2: output "> c\n" is not input "b\n" with prefix "> "`,
+		},
+		{
+			prefix: "> ", in: "a", out: "",
+			err: `indent: line 1: output "" does not start with prefix "> "`,
+		},
+		{
+			prefix: "> ", in: "", out: "> ",
+			err: `indent: line 1: output "> " follows the end of input`,
+		},
+	} {
+		err := Validate([]byte(tt.prefix), []byte(tt.in), []byte(tt.out))
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.err {
+			t.Errorf("Validate(%q, %q, %q) got error %q, want %q", tt.prefix, tt.in, tt.out, got, tt.err)
+		}
+	}
+}
+
+func TestValidateBytes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	pieces := []string{"a", "bc", " ", "\t", "\n", "\n\n"}
+	for i := 0; i < 1000; i++ {
+		var b strings.Builder
+		for j := r.Intn(20); j > 0; j-- {
+			b.WriteString(pieces[r.Intn(len(pieces))])
+		}
+		in := []byte(b.String())
+		for _, prefix := range []string{"", "\t", "> "} {
+			if err := Validate([]byte(prefix), in, Bytes([]byte(prefix), in)); err != nil {
+				t.Fatalf("Bytes(%q, %q): %v", prefix, in, err)
+			}
+		}
+	}
+}
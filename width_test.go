@@ -0,0 +1,43 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "testing"
+
+func TestDisplayWidth(t *testing.T) {
+	for _, tt := range []struct {
+		in    string
+		width int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"> ", 2},
+		{"\t", 8},
+		{"ab\t", 8},
+		{"ab\tc", 9},
+		{"héllo", 5},
+		{"héllo", 5}, // combining acute accent
+		{"日本", 4},
+		{"ｱ", 1}, // halfwidth katakana
+		{"Ａ", 2}, // fullwidth A
+		{"🙂", 2},
+		{"a\u200db", 2}, // zero width joiner
+		{"\x01", 0},
+	} {
+		if got := displayWidth(tt.in); got != tt.width {
+			t.Errorf("displayWidth(%q) got %d, want %d", tt.in, got, tt.width)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import "strings"
+
+// wrap breaks text into lines no wider than width columns, as measured on a
+// terminal, breaking at spaces.  Runs of whitespace between words are
+// collapsed to a single space.  A word wider than width is placed on a line by
+// itself.  Newlines in text are preserved as line breaks and blank lines.  If
+// width is not positive each line of text is only trimmed of spaces.
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		if width <= 0 {
+			lines = append(lines, strings.Join(words, " "))
+			continue
+		}
+		line := words[0]
+		n := displayWidth(line)
+		for _, word := range words[1:] {
+			wn := displayWidth(word)
+			if n+1+wn > width {
+				lines = append(lines, line)
+				line, n = word, wn
+				continue
+			}
+			line += " " + word
+			n += 1 + wn
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Hanging returns text wrapped so each line, including its prefix, is no wider
+// than width columns.  The first line is prefixed by first and the remaining
+// lines by rest.  This is a hanging indent when first is a label or list
+// marker and rest is spaces as wide as first.  Newlines in text are preserved
+// as in a paragraph, blank lines are written without trailing whitespace.
+// Each line of the result ends in a newline.  Hanging returns "" if text is
+// empty.  If width is not positive the lines are not wrapped.
+func Hanging(first, rest, text string, width int) string {
+	if text == "" {
+		return ""
+	}
+	if width > 0 {
+		pw := displayWidth(first)
+		if rw := displayWidth(rest); rw > pw {
+			pw = rw
+		}
+		if width -= pw; width < 1 {
+			width = 1
+		}
+	}
+	var b strings.Builder
+	prefix := first
+	for _, line := range wrap(text, width) {
+		if line == "" {
+			b.WriteString(strings.TrimRight(prefix, " \t"))
+		} else {
+			b.WriteString(prefix)
+			b.WriteString(line)
+		}
+		b.WriteByte('\n')
+		prefix = rest
+	}
+	return b.String()
+}
@@ -0,0 +1,66 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package indent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	for _, tt := range []struct {
+		in    string
+		width int
+		out   string
+	}{
+		{in: "", width: 10, out: ""},
+		{in: "a b c", width: 10, out: "a b c"},
+		{in: "  a   b  c ", width: 10, out: "a b c"},
+		{in: "the quick brown fox", width: 10, out: "the quick|brown fox"},
+		{in: "the quick brown fox", width: 9, out: "the quick|brown fox"},
+		{in: "the quick brown fox", width: 8, out: "the|quick|brown|fox"},
+		{in: "a verylongword b", width: 5, out: "a|verylongword|b"},
+		{in: "a b\n\nc d", width: 3, out: "a b||c d"},
+		{in: "héllo wörld", width: 11, out: "héllo wörld"},
+		{in: "he\u0301llo wo\u0308rld", width: 11, out: "he\u0301llo wo\u0308rld"},
+		{in: "日本 語", width: 4, out: "日本|語"},
+		{in: "a   b\nc", width: 0, out: "a b|c"},
+	} {
+		if got := strings.Join(wrap(tt.in, tt.width), "|"); got != tt.out {
+			t.Errorf("wrap(%q, %d) got %q, want %q", tt.in, tt.width, got, tt.out)
+		}
+	}
+}
+
+func TestHanging(t *testing.T) {
+	for _, tt := range []struct {
+		first, rest string
+		in          string
+		width       int
+		out         string
+	}{
+		{first: "- ", rest: "  ", in: "", width: 10, out: ""},
+		{first: "- ", rest: "  ", in: "a b c", width: 10, out: "- a b c\n"},
+		{first: "- ", rest: "  ", in: "the quick brown fox", width: 11, out: "- the quick\n  brown fox\n"},
+		{first: "name: ", rest: "      ", in: "a b c d", width: 9, out: "name: a b\n      c d\n"},
+		{first: "* ", rest: "  ", in: "a\n\nb", width: 10, out: "* a\n\n  b\n"},
+		{first: "1. ", rest: "   ", in: "a b", width: 0, out: "1. a b\n"},
+		{first: "- ", rest: "  ", in: "日本 語 x", width: 7, out: "- 日本\n  語 x\n"},
+	} {
+		if got := Hanging(tt.first, tt.rest, tt.in, tt.width); got != tt.out {
+			t.Errorf("Hanging(%q, %q, %q, %d) got %q, want %q", tt.first, tt.rest, tt.in, tt.width, got, tt.out)
+		}
+	}
+}
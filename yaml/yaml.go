@@ -0,0 +1,366 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package yaml is a small streaming YAML emitter that uses the indent package
+// to manage the nesting of collections.  It writes block style maps and
+// sequences, scalars, and literal block scalars, quoting scalars as needed.
+// For example,
+//
+//	e := yaml.NewEncoder(os.Stdout, 2)
+//	e.StartMap()
+//	e.Key("name")
+//	e.Scalar("web")
+//	e.Key("ports")
+//	e.StartSeq()
+//	e.Scalar(80)
+//	e.Scalar(443)
+//	e.EndSeq()
+//	e.Key("script")
+//	e.Block("make\nmake install\n")
+//	e.EndMap()
+//	e.Close()
+//
+// writes
+//
+//	name: web
+//	ports:
+//	  - 80
+//	  - 443
+//	script: |
+//	  make
+//	  make install
+package yaml
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pborman/indent"
+)
+
+// An Encoder writes a single YAML document to an io.Writer.  The methods of an
+// Encoder return an error if they are called out of order, such as calling
+// Scalar when a map key is expected, or if writing fails.  Once an error is
+// returned all subsequent calls return the same error.
+type Encoder struct {
+	w     io.Writer
+	unit  string   // the indentation of a nested collection
+	stack []*level // the open collections
+	done  bool     // the document's value has been written
+	err   error
+}
+
+// A level is an open map or sequence.
+type level struct {
+	seq     bool
+	w       io.Writer // where the contents are written
+	pw      io.Writer // where pending is written
+	pending string    // written before the first entry
+	empty   string    // written instead of pending if there are no entries
+	key     bool      // a map key is waiting for its value
+	n       int       // number of entries
+}
+
+// NewEncoder returns an Encoder that writes to w.  The entries of nested
+// collections are indented by width spaces, which must be at least 1.  An entry
+// of a sequence is always indented by two spaces, the width of "- ".
+func NewEncoder(w io.Writer, width int) *Encoder {
+	if width < 1 {
+		width = 1
+	}
+	// Writing through an indenter, even one without a prefix, lets the
+	// writers of nested collections know when a line has been started,
+	// such as by a "- ".  The option forces New to return an indenter.
+	w = indent.New(w, "", indent.WithReusedBuffer())
+	return &Encoder{w: w, unit: strings.Repeat(" ", width)}
+}
+
+func (e *Encoder) write(w io.Writer, s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(w, s)
+	}
+}
+
+func (e *Encoder) top() *level {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	return e.stack[len(e.stack)-1]
+}
+
+// open writes l's pending text before its first entry.
+func (e *Encoder) open(l *level) {
+	if l.n == 0 {
+		e.write(l.pw, l.pending)
+	}
+	l.n++
+}
+
+// value prepares for a value to be written.  It returns the writer the value
+// is written to, the text that must precede an inline value, and the writer
+// and indentation to use for the lines of a nested value.
+func (e *Encoder) value(what string) (w io.Writer, lead string, nested io.Writer, err error) {
+	if e.err != nil {
+		return nil, "", nil, e.err
+	}
+	l := e.top()
+	switch {
+	case l == nil:
+		if e.done {
+			return nil, "", nil, e.fail("yaml: %s after the end of the document", what)
+		}
+		e.done = true
+		return e.w, "", indent.New(e.w, e.unit), nil
+	case l.seq:
+		e.open(l)
+		return l.w, "- ", indent.New(l.w, "  "), nil
+	case !l.key:
+		return nil, "", nil, e.fail("yaml: %s where a map key is expected", what)
+	default:
+		l.key = false
+		return l.w, " ", indent.New(l.w, e.unit), nil
+	}
+}
+
+func (e *Encoder) fail(format string, v ...interface{}) error {
+	e.err = fmt.Errorf(format, v...)
+	return e.err
+}
+
+// Key writes the key of the next entry of the current map.
+func (e *Encoder) Key(key string) error {
+	if e.err != nil {
+		return e.err
+	}
+	l := e.top()
+	if l == nil || l.seq || l.key {
+		return e.fail("yaml: key %q where a value is expected", key)
+	}
+	e.open(l)
+	e.write(l.w, quote(key)+":")
+	l.key = true
+	return e.err
+}
+
+// Scalar writes v as a scalar value.  A nil v is written as null, a bool or a
+// number is written as a literal, with infinities and NaN written as .inf,
+// -.inf, and .nan, and anything else is formatted with fmt.Sprint and written
+// as a string, quoted if necessary to keep its value.
+func (e *Encoder) Scalar(v interface{}) error {
+	w, lead, _, err := e.value("scalar")
+	if err != nil {
+		return err
+	}
+	var s string
+	switch v := v.(type) {
+	case nil:
+		s = "null"
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s = fmt.Sprint(v)
+	case float32:
+		s = float(float64(v), fmt.Sprint(v))
+	case float64:
+		s = float(v, fmt.Sprint(v))
+	case string:
+		s = quote(v)
+	default:
+		s = quote(fmt.Sprint(v))
+	}
+	e.write(w, lead+s+"\n")
+	return e.err
+}
+
+// float returns the YAML literal for f, which fmt.Sprint formats as s.
+func float(f float64, s string) string {
+	switch {
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	case math.IsNaN(f):
+		return ".nan"
+	}
+	return s
+}
+
+// Block writes text as a literal block scalar.  The lines of text are written
+// as is, indented beneath the key or sequence entry.
+func (e *Encoder) Block(text string) error {
+	w, lead, nested, err := e.value("block scalar")
+	if err != nil {
+		return err
+	}
+	header := lead + "|"
+	if text == "" {
+		e.write(w, header+"-\n")
+		return e.err
+	}
+	// A leading space on the first line requires an explicit indentation
+	// indicator.
+	if text[0] == ' ' || text[0] == '\n' {
+		header += strconv.Itoa(e.indicator(lead))
+	}
+	// The chomping indicator preserves the number of trailing newlines.
+	switch trail := len(text) - len(strings.TrimRight(text, "\n")); {
+	case trail == 0:
+		header += "-"
+		text += "\n"
+	case trail > 1:
+		header += "+"
+	}
+	e.write(w, header+"\n")
+	e.write(nested, text)
+	return e.err
+}
+
+// indicator returns the indentation indicator for a block scalar preceded by
+// lead.
+func (e *Encoder) indicator(lead string) int {
+	if lead == "- " {
+		return 2
+	}
+	return len(e.unit)
+}
+
+// StartMap starts a map value.  It is ended by EndMap.
+func (e *Encoder) StartMap() error {
+	return e.start(false)
+}
+
+// EndMap ends the current map.
+func (e *Encoder) EndMap() error {
+	return e.end(false)
+}
+
+// StartSeq starts a sequence value.  It is ended by EndSeq.
+func (e *Encoder) StartSeq() error {
+	return e.start(true)
+}
+
+// EndSeq ends the current sequence.
+func (e *Encoder) EndSeq() error {
+	return e.end(true)
+}
+
+func (e *Encoder) start(seq bool) error {
+	what, empty := "map", "{}"
+	if seq {
+		what, empty = "sequence", "[]"
+	}
+	w, lead, nested, err := e.value(what)
+	if err != nil {
+		return err
+	}
+	l := &level{seq: seq, pw: w, empty: lead + empty + "\n"}
+	switch lead {
+	case "":
+		// The document's value.
+		l.w = w
+	case "- ":
+		// A collection in a sequence starts on the line of its "- ".
+		l.pending = lead
+		l.w = nested
+	default:
+		// A collection in a map starts on the line after its key.
+		l.pending = "\n"
+		l.w = nested
+	}
+	e.stack = append(e.stack, l)
+	return nil
+}
+
+func (e *Encoder) end(seq bool) error {
+	if e.err != nil {
+		return e.err
+	}
+	l := e.top()
+	what := "EndMap"
+	if seq {
+		what = "EndSeq"
+	}
+	if l == nil || l.seq != seq {
+		return e.fail("yaml: %s without a matching start", what)
+	}
+	if l.key {
+		return e.fail("yaml: %s where a map value is expected", what)
+	}
+	if l.n == 0 {
+		e.write(l.pw, l.empty)
+	}
+	e.stack = e.stack[:len(e.stack)-1]
+	return e.err
+}
+
+// Close returns an error if the document is not complete.  It does not close
+// the underlying writer.
+func (e *Encoder) Close() error {
+	switch {
+	case e.err != nil:
+		return e.err
+	case len(e.stack) > 0:
+		return e.fail("yaml: document closed with %d open collections", len(e.stack))
+	case !e.done:
+		return e.fail("yaml: document closed without a value")
+	}
+	return nil
+}
+
+// quote returns s as a plain scalar if that keeps its value and otherwise as
+// a double quoted scalar.
+func quote(s string) string {
+	if needsQuotes(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// needsQuotes returns true if s cannot be written as a plain scalar, either
+// because it is not valid as a plain scalar or because it would be taken as a
+// value other than a string.
+func needsQuotes(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if _, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return true
+	}
+	switch s {
+	case ".inf", ".Inf", ".INF", "-.inf", "+.inf", ".nan", ".NaN", ".NAN":
+		return true
+	}
+	if strings.IndexByte("-?:,[]{}#&*!|>'\"%@`", s[0]) >= 0 {
+		// "-", "?", and ":" may start a plain scalar when followed
+		// by a non-space, but quoting them is simpler and safe.
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || r == 0xfeff {
+			return true
+		}
+	}
+	return false
+}
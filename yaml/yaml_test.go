@@ -0,0 +1,268 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package yaml
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// doc runs the events on an encoder with the given indentation width and
+// returns the output.
+func doc(t *testing.T, width int, events func(e *Encoder)) string {
+	t.Helper()
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, width)
+	events(e)
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestEncoder(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		width  int
+		events func(e *Encoder)
+		out    string
+	}{{
+		name:   "scalar",
+		width:  2,
+		events: func(e *Encoder) { e.Scalar("hello") },
+		out:    "hello\n",
+	}, {
+		name:  "map",
+		width: 2,
+		events: func(e *Encoder) {
+			e.StartMap()
+			e.Key("name")
+			e.Scalar("web")
+			e.Key("ports")
+			e.StartSeq()
+			e.Scalar(80)
+			e.Scalar(443)
+			e.EndSeq()
+			e.Key("meta")
+			e.StartMap()
+			e.Key("a")
+			e.Scalar(true)
+			e.Key("b")
+			e.Scalar(nil)
+			e.EndMap()
+			e.Key("empty")
+			e.StartMap()
+			e.EndMap()
+			e.Key("none")
+			e.StartSeq()
+			e.EndSeq()
+			e.EndMap()
+		},
+		out: `name: web
+ports:
+  - 80
+  - 443
+meta:
+  a: true
+  b: null
+empty: {}
+none: []
+`,
+	}, {
+		name:  "sequence of maps",
+		width: 4,
+		events: func(e *Encoder) {
+			e.StartSeq()
+			e.StartMap()
+			e.Key("name")
+			e.Scalar("a")
+			e.Key("tags")
+			e.StartSeq()
+			e.Scalar("x")
+			e.EndSeq()
+			e.EndMap()
+			e.StartSeq()
+			e.Scalar(1)
+			e.Scalar(2)
+			e.EndSeq()
+			e.StartMap()
+			e.EndMap()
+			e.EndSeq()
+		},
+		out: `- name: a
+  tags:
+      - x
+- - 1
+  - 2
+- {}
+`,
+	}, {
+		name:  "block scalars",
+		width: 2,
+		events: func(e *Encoder) {
+			e.StartMap()
+			e.Key("script")
+			e.Block("make\n\nmake install\n")
+			e.Key("strip")
+			e.Block("no newline")
+			e.Key("keep")
+			e.Block("two\n\n")
+			e.Key("indented")
+			e.Block("  leading\n")
+			e.Key("list")
+			e.StartSeq()
+			e.Block(" x\n")
+			e.EndSeq()
+			e.Key("empty")
+			e.Block("")
+			e.EndMap()
+		},
+		out: `script: |
+  make
+  
+  make install
+strip: |-
+  no newline
+keep: |+
+  two
+  
+indented: |2
+    leading
+list:
+  - |2
+     x
+empty: |-
+`,
+	}, {
+		name:  "quoting",
+		width: 2,
+		events: func(e *Encoder) {
+			e.StartMap()
+			e.Key("a: b")
+			e.Scalar("")
+			e.Key("yes")
+			e.Scalar("no")
+			e.Key("n")
+			e.Scalar("0x1f")
+			e.Key("s")
+			e.Scalar("- item")
+			e.Key("t")
+			e.Scalar("multi\nline")
+			e.Key("u")
+			e.Scalar("plain text, ok")
+			e.Key("v")
+			e.Scalar("1.5")
+			e.Key("w")
+			e.Scalar(1.5)
+			e.EndMap()
+		},
+		out: `"a: b": ""
+"yes": "no"
+"n": "0x1f"
+s: "- item"
+t: "multi\nline"
+u: plain text, ok
+v: "1.5"
+w: 1.5
+`,
+	}, {
+		name:  "floats",
+		width: 2,
+		events: func(e *Encoder) {
+			e.StartSeq()
+			e.Scalar(math.Inf(1))
+			e.Scalar(math.Inf(-1))
+			e.Scalar(math.NaN())
+			e.Scalar(float32(math.Inf(1)))
+			e.Scalar(float32(0.1))
+			e.Scalar(".inf")
+			e.EndSeq()
+		},
+		out: `- .inf
+- -.inf
+- .nan
+- .inf
+- 0.1
+- ".inf"
+`,
+	}} {
+		if got := doc(t, tt.width, tt.events); got != tt.out {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.name, got, tt.out)
+		}
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		events func(e *Encoder) error
+		err    string
+	}{{
+		name:   "key at root",
+		events: func(e *Encoder) error { return e.Key("a") },
+		err:    `yaml: key "a" where a value is expected`,
+	}, {
+		name: "value without key",
+		events: func(e *Encoder) error {
+			e.StartMap()
+			return e.Scalar(1)
+		},
+		err: "yaml: scalar where a map key is expected",
+	}, {
+		name: "mismatched end",
+		events: func(e *Encoder) error {
+			e.StartMap()
+			return e.EndSeq()
+		},
+		err: "yaml: EndSeq without a matching start",
+	}, {
+		name: "missing value",
+		events: func(e *Encoder) error {
+			e.StartMap()
+			e.Key("a")
+			return e.EndMap()
+		},
+		err: "yaml: EndMap where a map value is expected",
+	}, {
+		name: "two documents",
+		events: func(e *Encoder) error {
+			e.Scalar(1)
+			return e.Scalar(2)
+		},
+		err: "yaml: scalar after the end of the document",
+	}, {
+		name: "unclosed",
+		events: func(e *Encoder) error {
+			e.StartSeq()
+			return e.Close()
+		},
+		err: "yaml: document closed with 1 open collections",
+	}, {
+		name:   "empty",
+		events: func(e *Encoder) error { return e.Close() },
+		err:    "yaml: document closed without a value",
+	}} {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf, 2)
+		err := tt.events(e)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got error %v, want %s", tt.name, err, tt.err)
+		}
+		if err2 := e.Close(); err2 != err {
+			t.Errorf("%s: error was not sticky: %v", tt.name, err2)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package yangtree renders YANG tree diagrams as described in RFC 8340 using
+// the indent package to manage the nesting.  For example, the module
+//
+//	m := &yangtree.Module{
+//		Name: "example",
+//		Nodes: []*yangtree.Node{{
+//			Flags: "rw",
+//			Name:  "top",
+//			Children: []*yangtree.Node{
+//				{Flags: "rw", Name: "name", Opts: "?", Type: "string"},
+//				{Flags: "ro", Name: "entry", Opts: "*", Keys: []string{"id"}},
+//			},
+//		}},
+//	}
+//
+// is rendered by yangtree.Write(os.Stdout, m) as
+//
+//	module: example
+//	  +--rw top
+//	     +--rw name?    string
+//	     +--ro entry* [id]
+package yangtree
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pborman/indent"
+)
+
+// A Module is the root of a tree diagram.
+type Module struct {
+	Name          string
+	Nodes         []*Node // data nodes
+	RPCs          []*Node // listed under "rpcs:"
+	Notifications []*Node // listed under "notifications:"
+}
+
+// A Node is a single node in a tree diagram.  The fields correspond to the
+// parts of a node line in RFC 8340:
+//
+//	<status>--<flags> <name><opts> <type> <if-features>
+type Node struct {
+	Status   string // "+" (the default) for current, "x" for deprecated, "o" for obsolete
+	Flags    string // "rw", "ro", "-w", "-x", "-n", "mp", or "-u"
+	Name     string // the node name such as "interface" or "(choice)"
+	Opts     string // "?", "*", "!" or the empty string
+	Type     string // the type of a leaf or leaf-list, or "->", "<anydata>" and the like
+	Keys     []string
+	Features []string // if-features the node depends on
+	Children []*Node
+}
+
+// Write writes the tree diagram for m to w.
+func Write(w io.Writer, m *Module) error {
+	if _, err := fmt.Fprintf(w, "module: %s\n", m.Name); err != nil {
+		return err
+	}
+	iw := indent.New(w, "  ")
+	if err := WriteNodes(iw, m.Nodes); err != nil {
+		return err
+	}
+	for _, s := range []struct {
+		name  string
+		nodes []*Node
+	}{
+		{"rpcs", m.RPCs},
+		{"notifications", m.Notifications},
+	} {
+		if len(s.nodes) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\n  %s:\n", s.name); err != nil {
+			return err
+		}
+		if err := WriteNodes(indent.New(iw, "  "), s.nodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteNodes writes nodes, and recursively their children, to w as siblings
+// in a tree diagram.  The types of the siblings are aligned.
+func WriteNodes(w io.Writer, nodes []*Node) error {
+	width := 0
+	for _, n := range nodes {
+		if l := len(n.Name) + len(n.Opts); l > width {
+			width = l
+		}
+	}
+	for i, n := range nodes {
+		if _, err := io.WriteString(w, n.line(width)+"\n"); err != nil {
+			return err
+		}
+		if len(n.Children) == 0 {
+			continue
+		}
+		// The vertical bar continues as long as there are more siblings.
+		prefix := "   "
+		if i < len(nodes)-1 {
+			prefix = "|  "
+		}
+		if err := WriteNodes(indent.New(w, prefix), n.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// line returns the line for n with the type starting at column width+3.
+func (n *Node) line(width int) string {
+	status := n.Status
+	if status == "" {
+		status = "+"
+	}
+	name := n.Name + n.Opts
+	line := status + "--" + n.Flags + " " + name
+	if n.Type != "" {
+		line += strings.Repeat(" ", width-len(name)+3) + n.Type
+	}
+	if len(n.Keys) > 0 {
+		line += " [" + strings.Join(n.Keys, " ") + "]"
+	}
+	if len(n.Features) > 0 {
+		line += " {" + strings.Join(n.Features, ",") + "}?"
+	}
+	return line
+}
@@ -0,0 +1,80 @@
+//   Copyright 2020 Paul Borman
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package yangtree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	m := &Module{
+		Name: "example-module",
+		Nodes: []*Node{
+			{
+				Flags: "rw",
+				Name:  "interfaces",
+				Children: []*Node{{
+					Flags: "rw",
+					Name:  "interface",
+					Opts:  "*",
+					Keys:  []string{"name"},
+					Children: []*Node{
+						{Flags: "rw", Name: "name", Type: "string"},
+						{Flags: "rw", Name: "description", Opts: "?", Type: "string"},
+						{Flags: "rw", Name: "enabled", Opts: "?", Type: "boolean", Features: []string{"if-mib"}},
+					},
+				}},
+			},
+			{
+				Status: "x",
+				Flags:  "ro",
+				Name:   "state",
+				Opts:   "!",
+				Children: []*Node{
+					{Flags: "ro", Name: "count", Type: "uint32"},
+				},
+			},
+		},
+		RPCs: []*Node{{
+			Flags: "-x",
+			Name:  "reset",
+			Children: []*Node{
+				{Flags: "-w", Name: "input"},
+			},
+		}},
+	}
+	want := `
+module: example-module
+  +--rw interfaces
+  |  +--rw interface* [name]
+  |     +--rw name           string
+  |     +--rw description?   string
+  |     +--rw enabled?       boolean {if-mib}?
+  x--ro state!
+     +--ro count   uint32
+
+  rpcs:
+    +---x reset
+       +---w input
+`[1:]
+	var buf bytes.Buffer
+	if err := Write(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}